@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// altairData is the page data structure of the rendered Altair page
+type altairData struct {
+	AltairVersion        string
+	Assets               map[string]AssetRef
+	BaseURL              string
+	Endpoint             string
+	SubscriptionEndpoint string
+	QueryString          string
+	VariablesString      string
+	Nonce                string
+}
+
+// renderAltair renders the Altair GUI
+func renderAltair(w http.ResponseWriter, t *template.Template, ctx IDEContext, assets map[string]AssetRef) {
+	var varsString string
+	if ctx.Params.VariableValues != nil {
+		vars, err := json.MarshalIndent(ctx.Params.VariableValues, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		varsString = string(vars)
+	}
+
+	d := altairData{
+		AltairVersion: altairVersion,
+		Assets:        assets,
+		// Altair lazily loads its chunks relatively to its main script
+		BaseURL:              strings.TrimSuffix(assets["main.js"].URL, "main.js"),
+		Endpoint:             ctx.Endpoint,
+		SubscriptionEndpoint: ctx.SubscriptionEndpoint,
+		QueryString:          ctx.Params.RequestString,
+		VariablesString:      varsString,
+		Nonce:                ctx.Nonce,
+	}
+	err := t.Execute(w, d)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// altairVersion is the current version of Altair
+const altairVersion = "5.2.13"
+
+// altairTemplate is the page template to render Altair
+const altairTemplate = `
+{{ define "index" }}
+<!--
+The request to this GraphQL server provided the header "Accept: text/html"
+and as a result has been presented Altair - an in-browser IDE for
+exploring GraphQL.
+
+If you wish to receive JSON, provide the header "Accept: application/json" or
+add "&raw" to the end of the URL within a browser.
+-->
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>Altair</title>
+  <meta name="robots" content="noindex" />
+  <meta name="referrer" content="origin">
+  <base href="{{ .BaseURL }}">
+  {{ template "stylesheet" index .Assets "styles.css" }}
+</head>
+<body>
+  <app-root>
+    <div class="loading-screen styled">
+      <div class="loading-screen-inner">
+        <div class="loading-screen-loading-indicator">Loading Altair GraphQL Client...</div>
+      </div>
+    </div>
+  </app-root>
+  {{ template "script" index .Assets "runtime.js" }}
+  {{ template "script" index .Assets "polyfills.js" }}
+  {{ template "script" index .Assets "main.js" }}
+  <script{{ with .Nonce }} nonce="{{ . }}"{{ end }}>
+    AltairGraphQL.init({
+      endpointURL: window.location.origin + {{ .Endpoint }},
+      subscriptionsEndpoint: {{ .SubscriptionEndpoint }},
+      initialQuery: {{ .QueryString }},
+      initialVariables: {{ .VariablesString }},
+    });
+  </script>
+</body>
+</html>
+{{ end }}
+`
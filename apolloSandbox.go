@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+)
+
+// apolloSandboxData is the page data structure of the rendered Apollo Sandbox
+// page
+type apolloSandboxData struct {
+	Assets          map[string]AssetRef
+	Endpoint        string
+	QueryString     string
+	VariablesString string
+	Nonce           string
+}
+
+// renderApolloSandbox renders the embedded Apollo Sandbox
+func renderApolloSandbox(w http.ResponseWriter, t *template.Template, ctx IDEContext, assets map[string]AssetRef) {
+	var varsString string
+	if ctx.Params.VariableValues != nil {
+		vars, err := json.Marshal(ctx.Params.VariableValues)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		varsString = string(vars)
+	}
+
+	d := apolloSandboxData{
+		Assets:          assets,
+		Endpoint:        ctx.Endpoint,
+		QueryString:     ctx.Params.RequestString,
+		VariablesString: varsString,
+		Nonce:           ctx.Nonce,
+	}
+	err := t.Execute(w, d)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// apolloSandboxVersion is the current version of the embeddable Apollo
+// Sandbox, which Apollo only publishes as the latest one, so that it is always
+// loaded from the CDN
+const apolloSandboxVersion = "_latest"
+
+// apolloSandboxTemplate is the page template to render the embedded Apollo
+// Sandbox
+const apolloSandboxTemplate = `
+{{ define "index" }}
+<!--
+The request to this GraphQL server provided the header "Accept: text/html"
+and as a result has been presented Apollo Sandbox - an in-browser IDE for
+exploring GraphQL.
+
+If you wish to receive JSON, provide the header "Accept: application/json" or
+add "&raw" to the end of the URL within a browser.
+-->
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>Apollo Sandbox</title>
+  <meta name="robots" content="noindex" />
+  <meta name="referrer" content="origin">
+  <style{{ with .Nonce }} nonce="{{ . }}"{{ end }}>
+    body {
+      height: 100%;
+      margin: 0;
+      overflow: hidden;
+      width: 100%;
+    }
+    #embedded-sandbox {
+      height: 100vh;
+    }
+  </style>
+</head>
+<body>
+  <div id="embedded-sandbox"></div>
+  {{ template "script" index .Assets "embeddable-sandbox.umd.production.min.js" }}
+  <script{{ with .Nonce }} nonce="{{ . }}"{{ end }}>
+    var initialState = {};
+    var query = {{ .QueryString }};
+    if (query) {
+      initialState.document = query;
+      initialState.variables = {{ .VariablesString }} ? JSON.parse({{ .VariablesString }}) : {};
+    }
+    new window.EmbeddedSandbox({
+      target: '#embedded-sandbox',
+      initialEndpoint: window.location.origin + {{ .Endpoint }},
+      initialState: initialState,
+      includeCookies: true,
+    });
+  </script>
+</body>
+</html>
+{{ end }}
+`
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+//go:generate sh assets/vendor.sh
+
+// embeddedAssets holds the versioned copies of the IDE assets, vendored into
+// the assets directory by assets/vendor.sh
+//
+//go:embed assets
+var embeddedAssets embed.FS
+
+// defaultAssetsFS is the file system the assets are self-hosted from unless
+// Config.AssetsFS is set
+var defaultAssetsFS fs.FS = mustSub(embeddedAssets, "assets")
+
+// mustSub returns the sub tree of fsys at dir
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// asset is a file loaded by an IDE page
+type asset struct {
+	// name is the path of the asset in the assets directory or in
+	// Config.AssetsFS, and under
+	// Config.AssetsPath when the assets are self-hosted
+	name string
+	// cdnURL is the URL of the asset when it is not self-hosted
+	cdnURL string
+}
+
+// AssetRef is the reference of an asset rendered in the IDE templates
+type AssetRef struct {
+	URL       string
+	Integrity string
+}
+
+// graphiqlModernAssets are the assets of the current GraphiQL page, keyed by
+// the name they are referenced with in graphiqlModernTemplate
+var graphiqlModernAssets = map[string]asset{
+	"graphql-ws.min.js": {
+		name:   "graphql-ws/" + graphqlWsVersion + "/graphql-ws.min.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphql-ws@" + graphqlWsVersion + "/umd/graphql-ws.min.js",
+	},
+	"graphiql.min.css": {
+		name:   "graphiql/" + graphiqlModernVersion + "/graphiql.min.css",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphiql@" + graphiqlModernVersion + "/graphiql.min.css",
+	},
+	"graphiql.min.js": {
+		name:   "graphiql/" + graphiqlModernVersion + "/graphiql.min.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphiql@" + graphiqlModernVersion + "/graphiql.min.js",
+	},
+	"plugin-explorer.css": {
+		name:   "graphiql-plugin-explorer/" + graphiqlExplorerVersion + "/style.css",
+		cdnURL: "//cdn.jsdelivr.net/npm/@graphiql/plugin-explorer@" + graphiqlExplorerVersion + "/dist/style.css",
+	},
+	"plugin-explorer.umd.js": {
+		name:   "graphiql-plugin-explorer/" + graphiqlExplorerVersion + "/index.umd.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/@graphiql/plugin-explorer@" + graphiqlExplorerVersion + "/dist/index.umd.js",
+	},
+	"react.production.min.js": {
+		name:   "react/" + reactVersion + "/react.production.min.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/react@" + reactVersion + "/umd/react.production.min.js",
+	},
+	"react-dom.production.min.js": {
+		name:   "react/" + reactVersion + "/react-dom.production.min.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/react-dom@" + reactVersion + "/umd/react-dom.production.min.js",
+	},
+}
+
+// graphiqlAssets are the assets of the legacy GraphiQL page, keyed by the name they
+// are referenced with in graphiqlTemplate
+var graphiqlAssets = map[string]asset{
+	"graphql-ws.min.js": {
+		name:   "graphql-ws/" + graphqlWsVersion + "/graphql-ws.min.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphql-ws@" + graphqlWsVersion + "/umd/graphql-ws.min.js",
+	},
+	"graphiql.css": {
+		name:   "graphiql/" + graphiqlVersion + "/graphiql.css",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphiql@" + graphiqlVersion + "/graphiql.css",
+	},
+	"graphiql.min.js": {
+		name:   "graphiql/" + graphiqlVersion + "/graphiql.min.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphiql@" + graphiqlVersion + "/graphiql.min.js",
+	},
+	"es6-promise.auto.min.js": {
+		name:   "es6-promise/4.0.5/es6-promise.auto.min.js",
+		cdnURL: "//cdn.jsdelivr.net/es6-promise/4.0.5/es6-promise.auto.min.js",
+	},
+	"fetch.min.js": {
+		name:   "fetch/0.9.0/fetch.min.js",
+		cdnURL: "//cdn.jsdelivr.net/fetch/0.9.0/fetch.min.js",
+	},
+	"react.min.js": {
+		name:   "react/15.4.2/react.min.js",
+		cdnURL: "//cdn.jsdelivr.net/react/15.4.2/react.min.js",
+	},
+	"react-dom.min.js": {
+		name:   "react/15.4.2/react-dom.min.js",
+		cdnURL: "//cdn.jsdelivr.net/react/15.4.2/react-dom.min.js",
+	},
+}
+
+// playgroundAssets are the assets of the Playground page, keyed by the name
+// they are referenced with in graphcoolPlaygroundTemplate
+var playgroundAssets = map[string]asset{
+	"index.css": {
+		name:   "graphql-playground-react/" + graphcoolPlaygroundVersion + "/static/css/index.css",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphql-playground-react/build/static/css/index.css",
+	},
+	"favicon.png": {
+		name:   "graphql-playground-react/" + graphcoolPlaygroundVersion + "/favicon.png",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphql-playground-react/build/favicon.png",
+	},
+	"middleware.js": {
+		name:   "graphql-playground-react/" + graphcoolPlaygroundVersion + "/static/js/middleware.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphql-playground-react/build/static/js/middleware.js",
+	},
+	"logo.png": {
+		name:   "graphql-playground-react/" + graphcoolPlaygroundVersion + "/logo.png",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphql-playground-react/build/logo.png",
+	},
+}
+
+// altairAssets are the assets of the Altair page, keyed by the name they are
+// referenced with in altairTemplate
+var altairAssets = map[string]asset{
+	"styles.css": {
+		name:   "altair-static/" + altairVersion + "/styles.css",
+		cdnURL: "//cdn.jsdelivr.net/npm/altair-static@" + altairVersion + "/build/dist/styles.css",
+	},
+	"runtime.js": {
+		name:   "altair-static/" + altairVersion + "/runtime.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/altair-static@" + altairVersion + "/build/dist/runtime.js",
+	},
+	"polyfills.js": {
+		name:   "altair-static/" + altairVersion + "/polyfills.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/altair-static@" + altairVersion + "/build/dist/polyfills.js",
+	},
+	"main.js": {
+		name:   "altair-static/" + altairVersion + "/main.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/altair-static@" + altairVersion + "/build/dist/main.js",
+	},
+}
+
+// apolloSandboxAssets are the assets of the Apollo Sandbox page, keyed by the
+// name they are referenced with in apolloSandboxTemplate
+var apolloSandboxAssets = map[string]asset{
+	"embeddable-sandbox.umd.production.min.js": {
+		name:   "apollo-sandbox/" + apolloSandboxVersion + "/embeddable-sandbox.umd.production.min.js",
+		cdnURL: "//embeddable-sandbox.cdn.apollographql.com/" + apolloSandboxVersion + "/embeddable-sandbox.umd.production.min.js",
+	},
+}
+
+// voyagerAssets are the assets of the GraphQL Voyager page, keyed by the name
+// they are referenced with in voyagerTemplate
+var voyagerAssets = map[string]asset{
+	"voyager.css": {
+		name:   "graphql-voyager/" + voyagerVersion + "/voyager.css",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphql-voyager@" + voyagerVersion + "/dist/voyager.css",
+	},
+	"voyager.standalone.js": {
+		name:   "graphql-voyager/" + voyagerVersion + "/voyager.standalone.js",
+		cdnURL: "//cdn.jsdelivr.net/npm/graphql-voyager@" + voyagerVersion + "/dist/voyager.standalone.js",
+	},
+}
+
+// assetsHandler serves the self-hosted assets under its path
+type assetsHandler struct {
+	path      string
+	files     map[string][]byte
+	etags     map[string]string
+	integrity map[string]string
+}
+
+// newAssetsHandler loads the given assets from the file system they were
+// vendored in by assets/vendor.sh, the embedded assets directory when nil. It
+// panics when one of them is missing.
+func newAssetsHandler(assetsFS fs.FS, assetsPath string, manifests ...map[string]asset) *assetsHandler {
+	if assetsFS == nil {
+		assetsFS = defaultAssetsFS
+	}
+	h := &assetsHandler{
+		path:      strings.TrimSuffix(assetsPath, "/") + "/",
+		files:     map[string][]byte{},
+		etags:     map[string]string{},
+		integrity: map[string]string{},
+	}
+	for _, manifest := range manifests {
+		for _, a := range manifest {
+			b, err := fs.ReadFile(assetsFS, a.name)
+			if err != nil {
+				panic(fmt.Sprintf("self-hosted asset %s is missing, run go generate to vendor it", a.name))
+			}
+			sum := sha256.Sum256(b)
+			integrity := sha512.Sum384(b)
+			h.files[a.name] = b
+			h.etags[a.name] = `"` + hex.EncodeToString(sum[:16]) + `"`
+			h.integrity[a.name] = "sha384-" + base64.StdEncoding.EncodeToString(integrity[:])
+		}
+	}
+	return h
+}
+
+// refs returns the references of the assets of a page, whose paths are
+// resolved with pathFn. Without assets handler, the assets are loaded from the
+// CDN.
+func (h *assetsHandler) refs(manifest map[string]asset, pathFn func(p string) string) map[string]AssetRef {
+	refs := make(map[string]AssetRef, len(manifest))
+	for key, a := range manifest {
+		if h == nil {
+			refs[key] = AssetRef{URL: a.cdnURL}
+			continue
+		}
+		refs[key] = AssetRef{
+			URL:       pathFn(h.path + a.name),
+			Integrity: h.integrity[a.name],
+		}
+	}
+	return refs
+}
+
+// serves reports whether the request is for an asset. The assets path is
+// matched against the path of the handler, without the prefix it is mounted
+// under.
+func (h *assetsHandler) serves(r *http.Request) bool {
+	return h != nil && strings.HasPrefix(r.URL.Path, h.path)
+}
+
+// ServeHTTP serves an asset. The asset paths are versioned, so the assets can
+// be cached forever.
+func (h *assetsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, h.path)
+	b, ok := h.files[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", h.etags[name])
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(b))
+}
+
+// assetsTemplate defines the templates rendering the stylesheets and the
+// scripts of the IDE pages from their AssetRef
+const assetsTemplate = `
+{{ define "stylesheet" }}<link href="{{ .URL }}" rel="stylesheet"{{ with .Integrity }} integrity="{{ . }}" crossorigin="anonymous"{{ end }} />{{ end }}
+{{ define "script" }}<script src="{{ .URL }}"{{ with .Integrity }} integrity="{{ . }}" crossorigin="anonymous"{{ end }}></script>{{ end }}
+`
@@ -0,0 +1,54 @@
+#!/bin/sh
+# Vendors the assets of the IDE pages into the assets directory, where they
+# are embedded and self-hosted with Config.AssetsPath, or into the given
+# directory for Config.AssetsFS. The versions must match the ones of
+# assets.go.
+#
+#   sh assets/vendor.sh [directory]
+set -e
+
+dir="${1:-$(dirname "$0")}"
+mkdir -p "$dir"
+cd "$dir"
+
+fetch() {
+	mkdir -p "$(dirname "$1")"
+	curl -fsSL -o "$1" "$2"
+}
+
+GRAPHIQL_VERSION=0.11.11
+GRAPHIQL_MODERN_VERSION=3.1.1
+GRAPHIQL_EXPLORER_VERSION=1.0.3
+REACT_VERSION=18.2.0
+GRAPHQL_WS_VERSION=5.14.0
+PLAYGROUND_VERSION=1.5.2
+ALTAIR_VERSION=5.2.13
+VOYAGER_VERSION=2.0.0
+
+fetch graphiql/$GRAPHIQL_MODERN_VERSION/graphiql.min.css https://cdn.jsdelivr.net/npm/graphiql@$GRAPHIQL_MODERN_VERSION/graphiql.min.css
+fetch graphiql/$GRAPHIQL_MODERN_VERSION/graphiql.min.js https://cdn.jsdelivr.net/npm/graphiql@$GRAPHIQL_MODERN_VERSION/graphiql.min.js
+fetch graphiql-plugin-explorer/$GRAPHIQL_EXPLORER_VERSION/style.css https://cdn.jsdelivr.net/npm/@graphiql/plugin-explorer@$GRAPHIQL_EXPLORER_VERSION/dist/style.css
+fetch graphiql-plugin-explorer/$GRAPHIQL_EXPLORER_VERSION/index.umd.js https://cdn.jsdelivr.net/npm/@graphiql/plugin-explorer@$GRAPHIQL_EXPLORER_VERSION/dist/index.umd.js
+fetch react/$REACT_VERSION/react.production.min.js https://cdn.jsdelivr.net/npm/react@$REACT_VERSION/umd/react.production.min.js
+fetch react/$REACT_VERSION/react-dom.production.min.js https://cdn.jsdelivr.net/npm/react-dom@$REACT_VERSION/umd/react-dom.production.min.js
+fetch graphql-ws/$GRAPHQL_WS_VERSION/graphql-ws.min.js https://cdn.jsdelivr.net/npm/graphql-ws@$GRAPHQL_WS_VERSION/umd/graphql-ws.min.js
+
+fetch graphiql/$GRAPHIQL_VERSION/graphiql.css https://cdn.jsdelivr.net/npm/graphiql@$GRAPHIQL_VERSION/graphiql.css
+fetch graphiql/$GRAPHIQL_VERSION/graphiql.min.js https://cdn.jsdelivr.net/npm/graphiql@$GRAPHIQL_VERSION/graphiql.min.js
+fetch es6-promise/4.0.5/es6-promise.auto.min.js https://cdn.jsdelivr.net/npm/es6-promise@4.0.5/dist/es6-promise.auto.min.js
+fetch fetch/0.9.0/fetch.min.js https://cdn.jsdelivr.net/npm/whatwg-fetch@0.9.0/fetch.js
+fetch react/15.4.2/react.min.js https://cdn.jsdelivr.net/npm/react@15.4.2/dist/react.min.js
+fetch react/15.4.2/react-dom.min.js https://cdn.jsdelivr.net/npm/react-dom@15.4.2/dist/react-dom.min.js
+
+fetch graphql-playground-react/$PLAYGROUND_VERSION/static/css/index.css https://cdn.jsdelivr.net/npm/graphql-playground-react@$PLAYGROUND_VERSION/build/static/css/index.css
+fetch graphql-playground-react/$PLAYGROUND_VERSION/favicon.png https://cdn.jsdelivr.net/npm/graphql-playground-react@$PLAYGROUND_VERSION/build/favicon.png
+fetch graphql-playground-react/$PLAYGROUND_VERSION/static/js/middleware.js https://cdn.jsdelivr.net/npm/graphql-playground-react@$PLAYGROUND_VERSION/build/static/js/middleware.js
+fetch graphql-playground-react/$PLAYGROUND_VERSION/logo.png https://cdn.jsdelivr.net/npm/graphql-playground-react@$PLAYGROUND_VERSION/build/logo.png
+
+fetch altair-static/$ALTAIR_VERSION/styles.css https://cdn.jsdelivr.net/npm/altair-static@$ALTAIR_VERSION/build/dist/styles.css
+fetch altair-static/$ALTAIR_VERSION/runtime.js https://cdn.jsdelivr.net/npm/altair-static@$ALTAIR_VERSION/build/dist/runtime.js
+fetch altair-static/$ALTAIR_VERSION/polyfills.js https://cdn.jsdelivr.net/npm/altair-static@$ALTAIR_VERSION/build/dist/polyfills.js
+fetch altair-static/$ALTAIR_VERSION/main.js https://cdn.jsdelivr.net/npm/altair-static@$ALTAIR_VERSION/build/dist/main.js
+
+fetch graphql-voyager/$VOYAGER_VERSION/voyager.css https://cdn.jsdelivr.net/npm/graphql-voyager@$VOYAGER_VERSION/dist/voyager.css
+fetch graphql-voyager/$VOYAGER_VERSION/voyager.standalone.js https://cdn.jsdelivr.net/npm/graphql-voyager@$VOYAGER_VERSION/dist/voyager.standalone.js
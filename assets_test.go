@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/graphql-go/graphql/testutil"
+)
+
+// vendoredAssets returns fake vendored assets of the given manifests
+func vendoredAssets(manifests ...map[string]asset) fstest.MapFS {
+	vendored := fstest.MapFS{}
+	for _, manifest := range manifests {
+		for _, a := range manifest {
+			vendored[a.name] = &fstest.MapFile{Data: []byte("/* " + a.name + " */")}
+		}
+	}
+	return vendored
+}
+
+// allVendoredAssets returns fake vendored assets of every built-in IDE
+func allVendoredAssets() fstest.MapFS {
+	return vendoredAssets(graphiqlModernAssets, graphiqlAssets, playgroundAssets, altairAssets, voyagerAssets)
+}
+
+func TestHandler_AssetsPath(t *testing.T) {
+	h := New(&Config{
+		Schema:     &testutil.StarWarsSchema,
+		GraphiQL:   true,
+		AssetsPath: "/graphql/assets",
+		AssetsFS:   allVendoredAssets(),
+	})
+
+	req, _ := http.NewRequest("GET", "/graphql", nil)
+	req.Header.Set("Accept", "text/html")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+
+	body := resp.Body.String()
+	if strings.Contains(body, "cdn.jsdelivr.net") {
+		t.Fatalf("expected no CDN asset, got %s", body)
+	}
+	expectedBodyContains := []string{
+		`<script src="/graphql/assets/graphiql/` + graphiqlModernVersion + `/graphiql.min.js" integrity="sha384-`,
+		`<link href="/graphql/assets/graphiql/` + graphiqlModernVersion + `/graphiql.min.css" rel="stylesheet" integrity="sha384-`,
+	}
+	for _, e := range expectedBodyContains {
+		if !strings.Contains(body, e) {
+			t.Fatalf("wrong body, expected %s to contain %s", body, e)
+		}
+	}
+
+	req, _ = http.NewRequest("GET", "/graphql/assets/react/"+reactVersion+"/react.production.min.js", nil)
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+	if body := resp.Body.String(); body != "/* react/"+reactVersion+"/react.production.min.js */" {
+		t.Fatalf("wrong asset body %s", body)
+	}
+	if contentType := resp.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/javascript") {
+		t.Fatalf("wrong content type %s", contentType)
+	}
+	if cacheControl := resp.Header().Get("Cache-Control"); cacheControl != "public, max-age=31536000, immutable" {
+		t.Fatalf("wrong Cache-Control %s", cacheControl)
+	}
+
+	req.Header.Set("If-None-Match", resp.Header().Get("ETag"))
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNotModified {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/graphql/assets/unknown.js", nil)
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+}
+
+func TestHandler_AssetsPath_MissingAssets(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected to panic, did not panic")
+		}
+	}()
+	New(&Config{
+		Schema:     &testutil.StarWarsSchema,
+		GraphiQL:   true,
+		AssetsPath: "/graphql/assets",
+		AssetsFS:   fstest.MapFS{},
+	})
+}
+
+func TestHandler_AssetsPath_EmbeddedAssets(t *testing.T) {
+	defaultAssetsFS = allVendoredAssets()
+	t.Cleanup(func() {
+		defaultAssetsFS = mustSub(embeddedAssets, "assets")
+	})
+
+	h := New(&Config{
+		Schema:     &testutil.StarWarsSchema,
+		GraphiQL:   true,
+		AssetsPath: "/graphql/assets",
+	})
+
+	req, _ := http.NewRequest("GET", "/graphql/assets/graphiql/"+graphiqlModernVersion+"/graphiql.min.js", nil)
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+}
+
+func TestHandler_AssetsPath_OnlyRenderedIDEs(t *testing.T) {
+	h := New(&Config{
+		Schema:     &testutil.StarWarsSchema,
+		IDE:        &IDEConfig{Default: IDEVoyager},
+		AssetsPath: "/graphql/assets",
+		AssetsFS:   vendoredAssets(voyagerAssets),
+	})
+
+	req, _ := http.NewRequest("GET", "/graphql/assets/graphql-voyager/"+voyagerVersion+"/voyager.standalone.js", nil)
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+}
+
+func TestHandler_AssetsPath_ContentSecurityPolicy(t *testing.T) {
+	h := New(&Config{
+		Schema:                &testutil.StarWarsSchema,
+		GraphiQL:              true,
+		AssetsPath:            "/graphql/assets",
+		AssetsFS:              allVendoredAssets(),
+		ContentSecurityPolicy: &ContentSecurityPolicyConfig{},
+	})
+
+	req, _ := http.NewRequest("GET", "/graphql", nil)
+	req.Header.Set("Accept", "text/html")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+
+	policy := resp.Header().Get("Content-Security-Policy")
+	if !strings.Contains(policy, "script-src 'self' 'nonce-") {
+		t.Fatalf("wrong policy, expected %s to only allow self-hosted scripts", policy)
+	}
+	if strings.Contains(policy, "cdn.jsdelivr.net") {
+		t.Fatalf("wrong policy, expected %s to not allow the CDN", policy)
+	}
+}
+
+func TestHandler_AssetsPath_ApolloSandboxFromCDN(t *testing.T) {
+	h := New(&Config{
+		Schema:                &testutil.StarWarsSchema,
+		IDE:                   &IDEConfig{Default: IDEApolloSandbox},
+		AssetsPath:            "/graphql/assets",
+		AssetsFS:              fstest.MapFS{},
+		ContentSecurityPolicy: &ContentSecurityPolicyConfig{},
+	})
+
+	req, _ := http.NewRequest("GET", "/graphql", nil)
+	req.Header.Set("Accept", "text/html")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+
+	if body := resp.Body.String(); !strings.Contains(body, `<script src="//embeddable-sandbox.cdn.apollographql.com/`+apolloSandboxVersion+`/`) {
+		t.Fatalf("expected the Apollo Sandbox to be loaded from the CDN, got %s", body)
+	}
+	if policy := resp.Header().Get("Content-Security-Policy"); !strings.Contains(policy, "https://embeddable-sandbox.cdn.apollographql.com") {
+		t.Fatalf("wrong policy, expected %s to allow the Apollo Sandbox CDN", policy)
+	}
+}
+
+func TestHandler_AssetsPath_PathPrefix(t *testing.T) {
+	h := http.StripPrefix("/api", New(&Config{
+		Schema:     &testutil.StarWarsSchema,
+		GraphiQL:   true,
+		AssetsPath: "/graphql/assets",
+		AssetsFS:   allVendoredAssets(),
+		Endpoints:  &EndpointConfig{PathPrefix: "/api"},
+	}))
+
+	req, _ := http.NewRequest("GET", "/api/graphql", nil)
+	req.Header.Set("Accept", "text/html")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+
+	asset := "/api/graphql/assets/graphiql/" + graphiqlModernVersion + "/graphiql.min.js"
+	if body := resp.Body.String(); !strings.Contains(body, `<script src="`+asset+`"`) {
+		t.Fatalf("wrong body, expected %s to load %s", body, asset)
+	}
+
+	req, _ = http.NewRequest("GET", asset, nil)
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+}
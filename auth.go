@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+)
+
+// AuthenticateFn authenticates a request before it is parsed. It returns the
+// context of the request, enriched with the Principal with WithPrincipal, or
+// an error rejecting the request with a 401 status. The rejected requests are
+// answered with the Config.AuthenticateChallenge WWW-Authenticate header,
+// which defaults to "Bearer".
+type AuthenticateFn func(r *http.Request) (context.Context, error)
+
+// Principal is the authenticated client of a request
+type Principal struct {
+	ID     string
+	Roles  []string
+	Scopes []string
+	// Claims holds any other information about the principal
+	Claims map[string]interface{}
+}
+
+// defaultAuthenticateChallenge is the WWW-Authenticate header sent when no
+// challenge is configured
+const defaultAuthenticateChallenge = "Bearer"
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a copy of ctx holding the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal held by ctx, if any. It can be
+// called from the resolvers with the context of graphql.ResolveParams.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// authenticate runs the authenticate hook on the request and returns the
+// enriched context. Rejected requests are answered with a 401 status.
+func (h *Handler) authenticate(ctx context.Context, w http.ResponseWriter, r *http.Request) (context.Context, bool) {
+	authCtx, err := h.authenticateFn(r.WithContext(ctx))
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", h.authenticateChallenge)
+		writeErrors(w, http.StatusUnauthorized, newCodedError(err.Error(), ErrorCodeUnauthenticated))
+		return nil, false
+	}
+	if authCtx == nil {
+		authCtx = ctx
+	}
+	return authCtx, true
+}
@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_Authenticate(t *testing.T) {
+	whoamiQuery := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"whoami": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					principal, ok := handler.PrincipalFromContext(p.Context)
+					if !ok {
+						return nil, nil
+					}
+					return principal.ID, nil
+				},
+			},
+		},
+	})
+	whoamiSchema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: whoamiQuery,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := handler.New(&handler.Config{
+		Schema: &whoamiSchema,
+		Authenticate: func(r *http.Request) (context.Context, error) {
+			switch r.Header.Get("Authorization") {
+			case "":
+				return r.Context(), nil
+			case "Bearer luke":
+				return handler.WithPrincipal(r.Context(), &handler.Principal{ID: "luke"}), nil
+			default:
+				return nil, errors.New("invalid token")
+			}
+		},
+		AuthenticateChallenge: `Bearer realm="graphql"`,
+	})
+
+	cases := map[string]struct {
+		authorization string
+		expectedCode  int
+		expectedData  interface{}
+	}{
+		"anonymous request": {
+			expectedCode: http.StatusOK,
+			expectedData: map[string]interface{}{"whoami": nil},
+		},
+		"authenticated request": {
+			authorization: "Bearer luke",
+			expectedCode:  http.StatusOK,
+			expectedData:  map[string]interface{}{"whoami": "luke"},
+		},
+		"rejected request": {
+			authorization: "Bearer vader",
+			expectedCode:  http.StatusUnauthorized,
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/graphql?query={whoami}", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			result, resp := executeTest(t, h, req)
+			if resp.Code != tc.expectedCode {
+				t.Fatalf("unexpected server response %v", resp.Code)
+			}
+			if !reflect.DeepEqual(result.Data, tc.expectedData) {
+				t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(tc.expectedData, result.Data))
+			}
+			if tc.expectedCode != http.StatusUnauthorized {
+				return
+			}
+			if challenge := resp.Header().Get("WWW-Authenticate"); challenge != `Bearer realm="graphql"` {
+				t.Fatalf("wrong WWW-Authenticate header: %v", challenge)
+			}
+			if len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != handler.ErrorCodeUnauthenticated {
+				t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// AuthRequirement lists what a principal needs to access a type or a field:
+// at least one of the roles, if any, and all of the scopes.
+type AuthRequirement struct {
+	Roles  []string
+	Scopes []string
+}
+
+// AuthRules maps "Type.field" or "Type" keys to their requirements. A "Type"
+// requirement applies to the fields of the type as well as to the fields
+// returning the type.
+type AuthRules map[string]AuthRequirement
+
+type AuthorizationConfig struct {
+	Rules AuthRules
+	// FailOperation rejects the whole operation during validation when it
+	// selects an unauthorized field, or a field of an interface one of whose
+	// possible types is unauthorized. Otherwise the unauthorized fields
+	// resolve to null with a FORBIDDEN error at their path.
+	FailOperation bool
+}
+
+// allows reports whether the principal satisfies the requirement
+func (req AuthRequirement) allows(principal *Principal) bool {
+	if principal == nil {
+		return false
+	}
+	if len(req.Roles) > 0 && !containsAny(principal.Roles, req.Roles) {
+		return false
+	}
+	for _, scope := range req.Scopes {
+		if !containsAny(principal.Scopes, []string{scope}) {
+			return false
+		}
+	}
+	return true
+}
+
+// authorize returns the key of the first rule denying the principal access to
+// the field of the parent type returning returnType, if any
+func (rules AuthRules) authorize(principal *Principal, parentType string, fieldName string, returnType graphql.Type) (string, bool) {
+	keys := []string{parentType, parentType + "." + fieldName}
+	if named, ok := graphql.GetNamed(returnType).(interface{ Name() string }); ok {
+		keys = append(keys, named.Name())
+	}
+	for _, key := range keys {
+		if req, ok := rules[key]; ok && !req.allows(principal) {
+			return key, false
+		}
+	}
+	return "", true
+}
+
+// forbiddenError is returned by the unauthorized fields
+type forbiddenError struct {
+	key string
+}
+
+func (e forbiddenError) Error() string {
+	return fmt.Sprintf("Not authorized to access %q.", e.key)
+}
+
+func (e forbiddenError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": ErrorCodeForbidden}
+}
+
+// authorizationFieldMiddleware returns a FieldMiddleware resolving the
+// unauthorized fields to null with a FORBIDDEN error
+func authorizationFieldMiddleware(rules AuthRules) FieldMiddleware {
+	return func(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			principal, _ := PrincipalFromContext(p.Context)
+			if key, ok := rules.authorize(principal, p.Info.ParentType.Name(), p.Info.FieldName, p.Info.ReturnType); !ok {
+				return nil, forbiddenError{key: key}
+			}
+			return next(p)
+		}
+	}
+}
+
+// authorizationRule returns a validation rule rejecting the documents which
+// select a field the principal of the request is not authorized to access
+func authorizationRule(schema *graphql.Schema, rules AuthRules) validationRuleFn {
+	return func(ctx context.Context, doc *ast.Document) []gqlerrors.FormattedError {
+		principal, _ := PrincipalFromContext(ctx)
+		v := &authorizationVisitor{
+			schema:    schema,
+			rules:     rules,
+			principal: principal,
+			fragments: fragmentDefinitions(doc),
+			visiting:  map[string]bool{},
+		}
+		for _, definition := range doc.Definitions {
+			operation, ok := definition.(*ast.OperationDefinition)
+			if !ok {
+				continue
+			}
+			var rootType *graphql.Object
+			switch operation.Operation {
+			case ast.OperationTypeMutation:
+				rootType = schema.MutationType()
+			case ast.OperationTypeSubscription:
+				rootType = schema.SubscriptionType()
+			default:
+				rootType = schema.QueryType()
+			}
+			v.visitSelectionSet(rootType, operation.SelectionSet)
+			if len(v.errs) > 0 {
+				return v.errs
+			}
+		}
+		return nil
+	}
+}
+
+// authorizationVisitor walks the selection sets along with their types and
+// reports the first unauthorized field
+type authorizationVisitor struct {
+	schema    *graphql.Schema
+	rules     AuthRules
+	principal *Principal
+	fragments map[string]*ast.FragmentDefinition
+	visiting  map[string]bool
+	errs      []gqlerrors.FormattedError
+}
+
+func (v *authorizationVisitor) visitSelectionSet(parentType graphql.Type, selectionSet *ast.SelectionSet) {
+	if parentType == nil || selectionSet == nil {
+		return
+	}
+	for _, selection := range selectionSet.Selections {
+		if len(v.errs) > 0 {
+			return
+		}
+		switch selection := selection.(type) {
+		case *ast.Field:
+			v.visitField(parentType, selection)
+		case *ast.InlineFragment:
+			v.visitSelectionSet(v.typeCondition(parentType, selection.TypeCondition), selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if selection.Name == nil || v.visiting[selection.Name.Value] {
+				continue
+			}
+			fragment, ok := v.fragments[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			v.visiting[selection.Name.Value] = true
+			v.visitSelectionSet(v.typeCondition(parentType, fragment.TypeCondition), fragment.SelectionSet)
+			v.visiting[selection.Name.Value] = false
+		}
+	}
+}
+
+func (v *authorizationVisitor) visitField(parentType graphql.Type, field *ast.Field) {
+	if field.Name == nil {
+		return
+	}
+	var fields graphql.FieldDefinitionMap
+	switch parentType := parentType.(type) {
+	case *graphql.Object:
+		fields = parentType.Fields()
+	case *graphql.Interface:
+		fields = parentType.Fields()
+	}
+	fieldDef, ok := fields[field.Name.Value]
+	if !ok {
+		// meta fields and unknown fields are left to the validation of graphql.Do
+		return
+	}
+
+	// the field of an interface resolves on any of its possible types
+	parentTypes := []string{parentType.Name()}
+	if abstractType, ok := parentType.(*graphql.Interface); ok {
+		for _, possibleType := range v.schema.PossibleTypes(abstractType) {
+			parentTypes = append(parentTypes, possibleType.Name())
+		}
+	}
+	for _, parentTypeName := range parentTypes {
+		if key, ok := v.rules.authorize(v.principal, parentTypeName, fieldDef.Name, fieldDef.Type); !ok {
+			v.errs = append(v.errs, newValidationError(forbiddenError{key: key}.Error(), ErrorCodeForbidden, field))
+			return
+		}
+	}
+	namedType, _ := graphql.GetNamed(fieldDef.Type).(graphql.Type)
+	v.visitSelectionSet(namedType, field.SelectionSet)
+}
+
+// typeCondition returns the type of a fragment, the parent type when the
+// fragment has no type condition
+func (v *authorizationVisitor) typeCondition(parentType graphql.Type, typeCondition *ast.Named) graphql.Type {
+	if typeCondition == nil || typeCondition.Name == nil {
+		return parentType
+	}
+	return v.schema.Type(typeCondition.Name.Value)
+}
+
+// containsAny reports whether values contains any of the wanted values
+func containsAny(values []string, wanted []string) bool {
+	for _, value := range values {
+		for _, w := range wanted {
+			if value == w {
+				return true
+			}
+		}
+	}
+	return false
+}
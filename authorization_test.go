@@ -0,0 +1,118 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_Authorization(t *testing.T) {
+	rules := handler.AuthRules{
+		"Account.email": {Scopes: []string{"read:email"}},
+		"Billing":       {Roles: []string{"admin", "accountant"}},
+	}
+	authenticate := func(r *http.Request) (context.Context, error) {
+		principal := &handler.Principal{ID: "luke", Roles: []string{"user"}}
+		if r.Header.Get("X-Admin") != "" {
+			principal.Roles = []string{"admin"}
+			principal.Scopes = []string{"read:email"}
+		}
+		return handler.WithPrincipal(r.Context(), principal), nil
+	}
+
+	cases := map[string]struct {
+		failOperation bool
+		admin         bool
+		expectedData  interface{}
+		expectedPaths [][]interface{}
+	}{
+		"authorized fields resolve": {
+			admin: true,
+			expectedData: map[string]interface{}{
+				"account": map[string]interface{}{
+					"name":    "luke",
+					"email":   "luke@rebellion.org",
+					"billing": map[string]interface{}{"iban": "XX00"},
+				},
+			},
+		},
+		"unauthorized fields resolve to null": {
+			expectedData: map[string]interface{}{
+				"account": map[string]interface{}{
+					"name":    "luke",
+					"email":   nil,
+					"billing": nil,
+				},
+			},
+			expectedPaths: [][]interface{}{{"account", "email"}, {"account", "billing"}},
+		},
+		"unauthorized fields fail the operation": {
+			failOperation: true,
+			expectedPaths: [][]interface{}{nil},
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			schema := newTestSchema(t)
+			h := handler.New(&handler.Config{
+				Schema:       &schema,
+				Authenticate: authenticate,
+				Authorization: &handler.AuthorizationConfig{
+					Rules:         rules,
+					FailOperation: tc.failOperation,
+				},
+			})
+
+			req, _ := http.NewRequest("GET", "/graphql?query={account{name email billing{iban}}}", nil)
+			if tc.admin {
+				req.Header.Set("X-Admin", "true")
+			}
+			result, resp := executeTest(t, h, req)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("unexpected server response %v", resp.Code)
+			}
+			if !reflect.DeepEqual(result.Data, tc.expectedData) {
+				t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(tc.expectedData, result.Data))
+			}
+			if len(result.Errors) != len(tc.expectedPaths) {
+				t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+			}
+			for _, err := range result.Errors {
+				if err.Extensions["code"] != handler.ErrorCodeForbidden {
+					t.Fatalf("wrong error code: %v", err.Extensions)
+				}
+				found := false
+				for _, path := range tc.expectedPaths {
+					found = found || reflect.DeepEqual(err.Path, path)
+				}
+				if !found {
+					t.Fatalf("unexpected error path %v", err.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_Authorization_FailOperationAbstractTypes(t *testing.T) {
+	h := handler.New(&handler.Config{
+		Schema: &testutil.StarWarsSchema,
+		Authorization: &handler.AuthorizationConfig{
+			Rules:         handler.AuthRules{"Droid": {Roles: []string{"admin"}}},
+			FailOperation: true,
+		},
+	})
+
+	req, _ := http.NewRequest("GET", "/graphql?query={hero{name}}", nil)
+	result, _ := executeTest(t, h, req)
+	if result.Data != nil {
+		t.Fatalf("expected the operation to be rejected, got %v", result.Data)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != handler.ErrorCodeForbidden {
+		t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+	}
+}
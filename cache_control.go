@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// CacheScope tells whether a cached response can be shared between clients
+type CacheScope string
+
+const (
+	CacheScopePublic  CacheScope = "PUBLIC"
+	CacheScopePrivate CacheScope = "PRIVATE"
+)
+
+// CacheHint is the cache policy of a type or a field. MaxAge is in seconds,
+// the scope defaults to PUBLIC.
+type CacheHint struct {
+	MaxAge int
+	Scope  CacheScope
+}
+
+// CacheHints maps "Type.field" or "Type" keys to their cache hints. A "Type"
+// hint applies to the fields returning the type, a "Type.field" hint taking
+// precedence over it.
+type CacheHints map[string]CacheHint
+
+// CacheControlConfig enables the HTTP caching of the queries sent with GET.
+// The Cache-Control header of a response is derived from the hints of the
+// resolved fields: the lowest maxAge wins, and a single PRIVATE field makes
+// the whole response private.
+type CacheControlConfig struct {
+	Hints CacheHints
+	// DefaultMaxAge applies to the root fields and to the fields returning
+	// an object type which have no hint. Other fields without hint inherit
+	// the policy of their parent.
+	DefaultMaxAge int
+}
+
+// cachePolicy collects the cache hints of the fields resolved for a request
+type cachePolicy struct {
+	mu         sync.Mutex
+	maxAge     int
+	hinted     bool
+	private    bool
+	uncachable bool
+}
+
+type cachePolicyContextKey struct{}
+
+// restrict lowers the policy to the given hint
+func (p *cachePolicy) restrict(hint CacheHint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.hinted || hint.MaxAge < p.maxAge {
+		p.maxAge = hint.MaxAge
+	}
+	p.hinted = true
+	if hint.Scope == CacheScopePrivate {
+		p.private = true
+	}
+}
+
+// hint returns the policy as a single hint, when a field has been hinted
+func (p *cachePolicy) hint() (CacheHint, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.hinted || p.uncachable {
+		return CacheHint{}, false
+	}
+	hint := CacheHint{MaxAge: p.maxAge, Scope: CacheScopePublic}
+	if p.private {
+		hint.Scope = CacheScopePrivate
+	}
+	return hint, true
+}
+
+// cacheControl returns the Cache-Control header value of the policy, or an
+// empty string when the response must not be cached
+func (p *cachePolicy) cacheControl() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.uncachable || !p.hinted || p.maxAge <= 0 {
+		return ""
+	}
+	if p.private {
+		return fmt.Sprintf("max-age=%d, private", p.maxAge)
+	}
+	return fmt.Sprintf("max-age=%d, public", p.maxAge)
+}
+
+// cacheControlFieldMiddleware returns a FieldMiddleware collecting the cache
+// hints of the resolved fields into the policy of the request context
+func cacheControlFieldMiddleware(config CacheControlConfig) FieldMiddleware {
+	return func(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			policy, ok := p.Context.Value(cachePolicyContextKey{}).(*cachePolicy)
+			if !ok {
+				return next(p)
+			}
+
+			if operation, ok := p.Info.Operation.(*ast.OperationDefinition); ok && operation.Operation != ast.OperationTypeQuery {
+				policy.mu.Lock()
+				policy.uncachable = true
+				policy.mu.Unlock()
+				return next(p)
+			}
+
+			named := graphql.GetNamed(p.Info.ReturnType)
+			hint, ok := config.Hints[p.Info.ParentType.Name()+"."+p.Info.FieldName]
+			if t, isType := named.(graphql.Type); !ok && isType {
+				hint, ok = config.Hints[t.Name()]
+			}
+			if _, isObject := named.(*graphql.Object); !ok && (isObject || p.Info.Path.Prev == nil) {
+				hint, ok = CacheHint{MaxAge: config.DefaultMaxAge}, true
+			}
+			if ok {
+				policy.restrict(hint)
+			}
+			return next(p)
+		}
+	}
+}
+
+// withCachePolicy returns a copy of ctx collecting the cache policy of the
+// request, when the request can be cached
+func withCachePolicy(ctx context.Context, r *http.Request) (context.Context, *cachePolicy) {
+	if r.Method != http.MethodGet {
+		return ctx, nil
+	}
+	policy := &cachePolicy{}
+	return context.WithValue(ctx, cachePolicyContextKey{}, policy), policy
+}
+
+// writeCacheHeaders sets the ETag and Cache-Control headers of a cacheable
+// response, and reports whether the client copy of the response is fresh. The
+// ETag of a body sent compressed is suffixed with its encoding.
+func writeCacheHeaders(w http.ResponseWriter, r *http.Request, policy *cachePolicy, result *graphql.Result, body []byte, encoding string) (notModified bool) {
+	if !writeCacheControl(w, policy, result) {
+		return false
+	}
+
+	sum := sha256.Sum256(etagBody(result, body))
+	etag := hex.EncodeToString(sum[:16])
+	if encoding != "" {
+		etag += "-" + encoding
+	}
+	etag = `"` + etag + `"`
+	w.Header().Set("ETag", etag)
+
+	return etagMatches(r.Header.Get("If-None-Match"), etag)
+}
+
+// etagBody returns what the ETag of a response is computed from: its body,
+// without the response cache status, so that the hits and the misses of the
+// response cache share their ETag
+func etagBody(result *graphql.Result, body []byte) []byte {
+	if _, ok := result.Extensions["responseCache"]; !ok {
+		return body
+	}
+	stripped := *result
+	stripped.Extensions = nil
+	for key, value := range result.Extensions {
+		if key == "responseCache" {
+			continue
+		}
+		if stripped.Extensions == nil {
+			stripped.Extensions = map[string]interface{}{}
+		}
+		stripped.Extensions[key] = value
+	}
+	b, _ := json.Marshal(&stripped)
+	return b
+}
+
+// writeCacheControl sets the Cache-Control header of a cacheable response and
+// reports whether the response is cacheable
+func writeCacheControl(w http.ResponseWriter, policy *cachePolicy, result *graphql.Result) bool {
+	if result.HasErrors() {
+		return false
+	}
+	policy.mu.Lock()
+	uncachable := policy.uncachable
+	policy.mu.Unlock()
+	if uncachable {
+		return false
+	}
+
+	if cacheControl := policy.cacheControl(); cacheControl != "" {
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+	return true
+}
+
+// etagMatches reports whether the If-None-Match header matches the etag
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
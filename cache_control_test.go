@@ -0,0 +1,98 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_CacheControl(t *testing.T) {
+	schema := newTestSchema(t)
+	h := handler.New(&handler.Config{
+		Schema: &schema,
+		CacheControl: &handler.CacheControlConfig{
+			Hints: handler.CacheHints{
+				"Product":       {MaxAge: 300},
+				"Product.price": {MaxAge: 60},
+				"Query.cart":    {MaxAge: 30, Scope: handler.CacheScopePrivate},
+			},
+		},
+	})
+
+	cases := map[string]struct {
+		method               string
+		query                string
+		expectedCacheControl string
+		expectETag           bool
+	}{
+		"uses the type hint": {
+			method:               "GET",
+			query:                "{product{name}}",
+			expectedCacheControl: "max-age=300, public",
+			expectETag:           true,
+		},
+		"uses the minimum maxAge": {
+			method:               "GET",
+			query:                "{product{name price}}",
+			expectedCacheControl: "max-age=60, public",
+			expectETag:           true,
+		},
+		"private scope wins": {
+			method:               "GET",
+			query:                "{product{name} cart{name}}",
+			expectedCacheControl: "max-age=30, private",
+			expectETag:           true,
+		},
+		"root fields without hint are not cached": {
+			method:     "GET",
+			query:      "{product{name} uncacheable}",
+			expectETag: true,
+		},
+		"mutations are not cached": {
+			method: "GET",
+			query:  "mutation{buy{name}}",
+		},
+		"errors are not cached": {
+			method: "GET",
+			query:  "{unknown}",
+		},
+		"POST requests are not cached": {
+			method: "POST",
+			query:  "{product{name}}",
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			req, _ := http.NewRequest(tc.method, "/graphql", nil)
+			req.URL.RawQuery = "query=" + tc.query
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("unexpected server response %v", resp.Code)
+			}
+			if cacheControl := resp.Header().Get("Cache-Control"); cacheControl != tc.expectedCacheControl {
+				t.Fatalf("wrong Cache-Control, expected %q, got %q", tc.expectedCacheControl, cacheControl)
+			}
+			etag := resp.Header().Get("ETag")
+			if (etag != "") != tc.expectETag {
+				t.Fatalf("unexpected ETag %q", etag)
+			}
+			if etag == "" {
+				return
+			}
+
+			req.Header.Set("If-None-Match", etag)
+			resp = httptest.NewRecorder()
+			h.ServeHTTP(resp, req)
+			if resp.Code != http.StatusNotModified {
+				t.Fatalf("unexpected server response %v", resp.Code)
+			}
+			if resp.Body.Len() != 0 {
+				t.Fatalf("expected empty body, got %s", resp.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxDecompressedSize is the maximum size in bytes of a compressed
+// request body once decompressed, used unless configured otherwise
+const DefaultMaxDecompressedSize = 10 << 20
+
+// Content encodings supported by the handler, deflate being the zlib format
+// as defined by HTTP
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// errDecompressedBodyTooLarge is returned when a decompressed request body is
+// larger than allowed
+var errDecompressedBodyTooLarge = errors.New("decompressed request body too large")
+
+// CompressionConfig enables the compression of the responses, negotiated from
+// the Accept-Encoding header of the request
+type CompressionConfig struct {
+	// MinSize is the minimum size in bytes of the responses to compress
+	MinSize int
+	// Level is the gzip or deflate compression level, zero meaning the
+	// default compression level
+	Level int
+	// MaxDecompressedSize is the maximum size in bytes of a compressed
+	// request body once decompressed, it defaults to DefaultMaxDecompressedSize
+	MaxDecompressedSize int64
+}
+
+// decompressBody replaces the body of a request sent with a Content-Encoding
+// by its decompressed content, reading at most maxSize bytes of it
+func decompressBody(r *http.Request, maxSize int64) error {
+	var reader io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+		return nil
+	case EncodingGzip:
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return err
+		}
+		reader = gz
+	case EncodingDeflate:
+		// the deflate coding of HTTP is the zlib format
+		zr, err := zlib.NewReader(r.Body)
+		if err != nil {
+			return err
+		}
+		reader = zr
+	default:
+		return errors.New("unsupported content encoding")
+	}
+	r.Body = &limitedReadCloser{reader: reader, body: r.Body, remaining: maxSize}
+	return nil
+}
+
+// limitedReadCloser reads from a decompressing reader, failing once more than
+// the remaining bytes have been read, and closes the original body
+type limitedReadCloser struct {
+	reader    io.ReadCloser
+	body      io.Closer
+	remaining int64
+}
+
+func (l *limitedReadCloser) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, errDecompressedBodyTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, errDecompressedBodyTooLarge
+	}
+	return n, err
+}
+
+func (l *limitedReadCloser) Close() error {
+	l.reader.Close()
+	return l.body.Close()
+}
+
+// negotiateEncoding returns the preferred encoding of the Accept-Encoding
+// header among the supported ones, or an empty string if none is acceptable.
+// The quality of an encoding listed explicitly overrides the one of "*".
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		tokens := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(tokens[0]))
+		q := 1.0
+		for _, param := range tokens[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		qualities[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	// gzip is preferred to deflate on equal quality
+	for _, coding := range []string{EncodingGzip, EncodingDeflate} {
+		q, ok := qualities[coding]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// encoding returns the encoding a response body of the given size is
+// compressed with, or an empty string when it is sent uncompressed
+func (c *CompressionConfig) encoding(r *http.Request, size int) string {
+	if size < c.MinSize {
+		return ""
+	}
+	return negotiateEncoding(r.Header.Get("Accept-Encoding"))
+}
+
+// writeCompressed writes the response body, compressed when the client
+// accepts it and the body is large enough
+func (c *CompressionConfig) writeCompressed(w http.ResponseWriter, r *http.Request, statusCode int, body []byte) {
+	var cw io.WriteCloser
+	if len(body) >= c.MinSize {
+		cw = c.compressWriter(w, r)
+	} else {
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+	w.WriteHeader(statusCode)
+	if cw == nil {
+		w.Write(body)
+		return
+	}
+	cw.Write(body)
+	cw.Close()
+}
+
+// compressWriter returns a writer compressing to w with the encoding accepted
+// by the client and sets the response headers accordingly. It returns nil
+// when the client accepts no supported encoding.
+func (c *CompressionConfig) compressWriter(w http.ResponseWriter, r *http.Request) io.WriteCloser {
+	w.Header().Add("Vary", "Accept-Encoding")
+	encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+	if encoding == "" {
+		return nil
+	}
+
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	var cw io.WriteCloser
+	var err error
+	if encoding == EncodingGzip {
+		cw, err = gzip.NewWriterLevel(w, level)
+	} else {
+		cw, err = zlib.NewWriterLevel(w, level)
+	}
+	if err != nil {
+		return nil
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Del("Content-Length")
+	return cw
+}
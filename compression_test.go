@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func compress(t *testing.T, encoding string, data []byte) *bytes.Buffer {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	if encoding == EncodingGzip {
+		w = gzip.NewWriter(&buf)
+	} else {
+		w = zlib.NewWriter(&buf)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return &buf
+}
+
+func TestRequestOptions_POST_ContentEncoding(t *testing.T) {
+	body := []byte(`{"query": "query RebelsShipsQuery { rebels { name } }"}`)
+	expected := &RequestOptions{
+		Query: "query RebelsShipsQuery { rebels { name } }",
+	}
+
+	for _, encoding := range []string{EncodingGzip, EncodingDeflate} {
+		req, _ := http.NewRequest("POST", "/graphql", compress(t, encoding, body))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Content-Encoding", encoding)
+		result := NewRequestOptions(req)
+
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("%s: wrong result, graphql result diff: %v", encoding, testutil.Diff(expected, result))
+		}
+	}
+}
+
+func TestRequestOptions_POST_ContentEncoding_TooLarge(t *testing.T) {
+	body := []byte(`{"query": "` + strings.Repeat(" ", 1<<20) + `{ rebels { name } }"}`)
+
+	req, _ := http.NewRequest("POST", "/graphql", compress(t, EncodingGzip, body))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Encoding", EncodingGzip)
+	result := newRequestOptions(req, 1024)
+
+	if !reflect.DeepEqual(result, &RequestOptions{}) {
+		t.Fatalf("expected empty request options, got %v", result)
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                         "",
+		"br":                       "",
+		"gzip":                     EncodingGzip,
+		"deflate":                  EncodingDeflate,
+		"deflate, gzip":            EncodingGzip,
+		"gzip;q=0.5, deflate":      EncodingDeflate,
+		"gzip;q=0, deflate;q=0":    "",
+		"*":                        EncodingGzip,
+		"gzip;q=0, *":              EncodingDeflate,
+		"*, gzip;q=0, deflate;q=0": "",
+		"br;q=1.0, deflate;q=0.8 ": EncodingDeflate,
+	}
+	for acceptEncoding, expected := range cases {
+		if encoding := negotiateEncoding(acceptEncoding); encoding != expected {
+			t.Fatalf("%q: wrong encoding, expected %q, got %q", acceptEncoding, expected, encoding)
+		}
+	}
+}
+
+func TestHandler_Compression(t *testing.T) {
+	h := New(&Config{
+		Schema: &testutil.StarWarsSchema,
+		Compression: &CompressionConfig{
+			MinSize: 64,
+		},
+	})
+
+	cases := map[string]struct {
+		query            string
+		acceptEncoding   string
+		expectedEncoding string
+	}{
+		"compresses with gzip": {
+			query:            "{hero{name friends{name}}}",
+			acceptEncoding:   "gzip",
+			expectedEncoding: EncodingGzip,
+		},
+		"compresses with deflate": {
+			query:            "{hero{name friends{name}}}",
+			acceptEncoding:   "deflate",
+			expectedEncoding: EncodingDeflate,
+		},
+		"does not compress small responses": {
+			query:          "{hero{name}}",
+			acceptEncoding: "gzip",
+		},
+		"does not compress without Accept-Encoding": {
+			query: "{hero{name friends{name}}}",
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/graphql", nil)
+			req.URL.RawQuery = "query=" + tc.query
+			req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, req)
+
+			if encoding := resp.Header().Get("Content-Encoding"); encoding != tc.expectedEncoding {
+				t.Fatalf("wrong Content-Encoding, expected %q, got %q", tc.expectedEncoding, encoding)
+			}
+			var body io.Reader = resp.Body
+			switch tc.expectedEncoding {
+			case EncodingGzip:
+				gz, err := gzip.NewReader(body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = gz
+			case EncodingDeflate:
+				zr, err := zlib.NewReader(body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = zr
+			}
+			b, err := ioutil.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(b), `"R2-D2"`) {
+				t.Fatalf("wrong body %s", b)
+			}
+		})
+	}
+}
+
+func TestHandler_Compression_ETag(t *testing.T) {
+	h := New(&Config{
+		Schema:       &testutil.StarWarsSchema,
+		CacheControl: &CacheControlConfig{DefaultMaxAge: 60},
+		Compression:  &CompressionConfig{MinSize: 64},
+	})
+
+	serve := func(acceptEncoding string, ifNoneMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/graphql?query={hero{name friends{name}}}", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		return resp
+	}
+
+	plainETag := serve("", "").Header().Get("ETag")
+	gzipETag := serve("gzip", "").Header().Get("ETag")
+	if !strings.HasSuffix(gzipETag, `-gzip"`) || strings.TrimSuffix(gzipETag, `-gzip"`)+`"` != plainETag {
+		t.Fatalf("expected the gzip ETag %s to be the ETag %s suffixed with the encoding", gzipETag, plainETag)
+	}
+	if resp := serve("gzip", gzipETag); resp.Code != http.StatusNotModified {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+	if resp := serve("", gzipETag); resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+}
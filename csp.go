@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ContentSecurityPolicyConfig configures the Content-Security-Policy of the
+// IDE pages. The inline scripts and styles of the pages are allowed with a
+// nonce generated for every response.
+type ContentSecurityPolicyConfig struct {
+	// Directives replace the default sources of the directives, e.g.
+	// "img-src": {"'self'", "data:"}. The nonce is added to the script-src
+	// and style-src directives.
+	Directives map[string][]string
+	// ReportOnly sends the policy with the
+	// Content-Security-Policy-Report-Only header, instead of enforcing it
+	ReportOnly bool
+}
+
+// cspDirectives is the order the default directives are written in
+var cspDirectives = []string{
+	"default-src",
+	"script-src",
+	"style-src",
+	"img-src",
+	"font-src",
+	"connect-src",
+	"frame-src",
+	"base-uri",
+	"object-src",
+}
+
+// contentSecurityPolicy writes the Content-Security-Policy of the IDE pages
+type contentSecurityPolicy struct {
+	header     string
+	directives []string
+	sources    map[string][]string
+}
+
+// newContentSecurityPolicy returns the policy of the given config, allowing
+// the assets of the built-in IDEs from the CDN unless they are self-hosted.
+// It returns nil without config.
+func newContentSecurityPolicy(config *ContentSecurityPolicyConfig, builtins map[string]builtinIDE, selfHosted bool) *contentSecurityPolicy {
+	if config == nil {
+		return nil
+	}
+
+	var origins []string
+	sources := map[string][]string{
+		"default-src": {"'self'"},
+		"connect-src": {"'self'", "ws:", "wss:"},
+		"object-src":  {"'none'"},
+	}
+	for _, ide := range builtins {
+		if !selfHosted || ide.cdnOnly {
+			for _, a := range ide.manifest {
+				if u, err := url.Parse("https:" + a.cdnURL); err == nil {
+					origins = appendSource(origins, "https://"+u.Host)
+				}
+			}
+		}
+		for directive, ideSources := range ide.csp {
+			for _, source := range ideSources {
+				sources[directive] = appendSource(sources[directive], source)
+			}
+		}
+	}
+	sort.Strings(origins)
+	assetSources := append([]string{"'self'"}, origins...)
+	for _, directive := range []string{"script-src", "style-src", "base-uri"} {
+		sources[directive] = append(sources[directive], assetSources...)
+	}
+	for _, directive := range []string{"img-src", "font-src"} {
+		sources[directive] = append(append(sources[directive], assetSources...), "data:")
+	}
+	for directive, directiveSources := range config.Directives {
+		sources[directive] = directiveSources
+	}
+
+	c := &contentSecurityPolicy{
+		header:  "Content-Security-Policy",
+		sources: sources,
+	}
+	if config.ReportOnly {
+		c.header = "Content-Security-Policy-Report-Only"
+	}
+	var others []string
+	for directive := range sources {
+		if !containsAny([]string{directive}, cspDirectives) {
+			others = append(others, directive)
+		}
+	}
+	sort.Strings(others)
+	for _, directive := range append(append([]string{}, cspDirectives...), others...) {
+		if _, ok := sources[directive]; ok {
+			c.directives = append(c.directives, directive)
+		}
+	}
+	return c
+}
+
+// apply writes the policy header with a new nonce, and returns the nonce. It
+// returns an empty nonce when there is no policy.
+func (c *contentSecurityPolicy) apply(w http.ResponseWriter) string {
+	if c == nil {
+		return ""
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(b)
+
+	policy := make([]string, 0, len(c.directives))
+	for _, directive := range c.directives {
+		sources := c.sources[directive]
+		if directive == "script-src" || directive == "style-src" {
+			sources = append(append([]string{}, sources...), "'nonce-"+nonce+"'")
+		}
+		policy = append(policy, strings.TrimSpace(directive+" "+strings.Join(sources, " ")))
+	}
+	w.Header().Set(c.header, strings.Join(policy, "; "))
+	return nonce
+}
+
+// appendSource appends a source to the sources, unless it is already there
+func appendSource(sources []string, source string) []string {
+	for _, s := range sources {
+		if s == source {
+			return sources
+		}
+	}
+	return append(sources, source)
+}
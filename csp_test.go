@@ -0,0 +1,134 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+var (
+	cspNonceRegexp  = regexp.MustCompile(`script-src [^;]*'nonce-([^']+)'`)
+	inlineTagRegexp = regexp.MustCompile(`<(script|style)([^>]*)>`)
+)
+
+// renderIDE renders the given IDE, and returns the response
+func renderIDE(t *testing.T, h *handler.Handler, ide string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/graphql?ide="+ide, nil)
+	req.Header.Set("Accept", "text/html")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", rr.Code)
+	}
+	return rr
+}
+
+func TestHandler_ContentSecurityPolicy(t *testing.T) {
+	h := handler.New(&handler.Config{
+		Schema:                &testutil.StarWarsSchema,
+		GraphiQL:              true,
+		IDE:                   &handler.IDEConfig{Selectable: true},
+		ContentSecurityPolicy: &handler.ContentSecurityPolicyConfig{},
+	})
+
+	ides := []string{handler.IDEGraphiQL, handler.IDEPlayground, handler.IDEAltair, handler.IDEApolloSandbox, handler.IDEVoyager}
+	nonces := map[string]bool{}
+	for _, ide := range ides {
+		rr := renderIDE(t, h, ide)
+
+		policy := rr.Header().Get("Content-Security-Policy")
+		match := cspNonceRegexp.FindStringSubmatch(policy)
+		if match == nil {
+			t.Fatalf("%s: expected a script nonce in policy %s", ide, policy)
+		}
+		nonce := match[1]
+		if nonces[nonce] {
+			t.Fatalf("%s: expected a new nonce, got %s again", ide, nonce)
+		}
+		nonces[nonce] = true
+		expectedPolicyContains := []string{
+			"default-src 'self'",
+			"style-src 'self' https://cdn.jsdelivr.net",
+			"'nonce-" + nonce + "'",
+			"connect-src 'self' ws: wss:",
+		}
+		for _, e := range expectedPolicyContains {
+			if !strings.Contains(policy, e) {
+				t.Fatalf("%s: wrong policy, expected %s to contain %s", ide, policy, e)
+			}
+		}
+
+		tags := inlineTagRegexp.FindAllStringSubmatch(rr.Body.String(), -1)
+		if len(tags) == 0 {
+			t.Fatalf("%s: expected scripts in %s", ide, rr.Body.String())
+		}
+		for _, tag := range tags {
+			if strings.Contains(tag[2], "src=") {
+				continue
+			}
+			if !strings.Contains(tag[2], `nonce="`+nonce+`"`) {
+				t.Fatalf("%s: expected inline tag %s to have nonce %s", ide, tag[0], nonce)
+			}
+		}
+	}
+
+	policy := renderIDE(t, h, handler.IDEApolloSandbox).Header().Get("Content-Security-Policy")
+	if !strings.Contains(policy, "frame-src https://sandbox.embed.apollographql.com") {
+		t.Fatalf("wrong policy, expected %s to allow the sandbox frame", policy)
+	}
+}
+
+func TestHandler_ContentSecurityPolicy_Directives(t *testing.T) {
+	h := handler.New(&handler.Config{
+		Schema:   &testutil.StarWarsSchema,
+		GraphiQL: true,
+		ContentSecurityPolicy: &handler.ContentSecurityPolicyConfig{
+			Directives: map[string][]string{
+				"script-src":  {"'self'", "https://assets.example.com"},
+				"report-uri":  {"/csp-reports"},
+				"connect-src": {"'self'"},
+			},
+			ReportOnly: true,
+		},
+	})
+
+	rr := renderIDE(t, h, "")
+	if policy := rr.Header().Get("Content-Security-Policy"); policy != "" {
+		t.Fatalf("expected no enforced policy, got %s", policy)
+	}
+	policy := rr.Header().Get("Content-Security-Policy-Report-Only")
+	nonce := cspNonceRegexp.FindStringSubmatch(policy)
+	if nonce == nil {
+		t.Fatalf("expected a script nonce in policy %s", policy)
+	}
+	expectedPolicyContains := []string{
+		"default-src 'self'; script-src 'self' https://assets.example.com 'nonce-" + nonce[1] + "'; ",
+		"; connect-src 'self'; ",
+		"; report-uri /csp-reports",
+	}
+	for _, e := range expectedPolicyContains {
+		if !strings.Contains(policy, e) {
+			t.Fatalf("wrong policy, expected %s to contain %s", policy, e)
+		}
+	}
+}
+
+func TestHandler_ContentSecurityPolicy_Disabled(t *testing.T) {
+	h := handler.New(&handler.Config{
+		Schema:   &testutil.StarWarsSchema,
+		GraphiQL: true,
+	})
+
+	rr := renderIDE(t, h, "")
+	if policy := rr.Header().Get("Content-Security-Policy"); policy != "" {
+		t.Fatalf("expected no policy, got %s", policy)
+	}
+	if body := rr.Body.String(); strings.Contains(body, "nonce=") {
+		t.Fatalf("expected no nonce in %s", body)
+	}
+}
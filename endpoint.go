@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// defaultSubscriptionPath is the path of the subscription endpoint, sibling to
+// the GraphQL endpoint
+const defaultSubscriptionPath = "/subscriptions"
+
+// EndpointConfig configures how the endpoints rendered in the IDEs are derived
+// from the requests
+type EndpointConfig struct {
+	// TrustedProxies are the IPs or CIDRs of the reverse proxies whose
+	// Forwarded, X-Forwarded-Proto, X-Forwarded-Host and X-Forwarded-Prefix
+	// headers are honored
+	TrustedProxies []string
+	// PathPrefix is the path the handler is mounted under, e.g. when the
+	// requests reach it through http.StripPrefix
+	PathPrefix string
+	// SubscriptionPath is the path of the subscription endpoint under the
+	// prefix, defaults to /subscriptions
+	SubscriptionPath string
+}
+
+// endpointResolver derives the endpoints of the IDEs from the requests
+type endpointResolver struct {
+	trustedProxies   []*net.IPNet
+	pathPrefix       string
+	subscriptionPath string
+}
+
+// newEndpointResolver returns the endpoint resolver of the given config, a nil
+// config trusting no proxy. It panics on an invalid trusted proxy.
+func newEndpointResolver(config *EndpointConfig) *endpointResolver {
+	e := &endpointResolver{subscriptionPath: defaultSubscriptionPath}
+	if config == nil {
+		return e
+	}
+	for _, proxy := range config.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if strings.Contains(proxy, ":") {
+				proxy += "/128"
+			} else {
+				proxy += "/32"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			panic(fmt.Sprintf("invalid trusted proxy %v: %v", proxy, err))
+		}
+		e.trustedProxies = append(e.trustedProxies, ipNet)
+	}
+	e.pathPrefix = strings.TrimSuffix(config.PathPrefix, "/")
+	if config.SubscriptionPath != "" {
+		e.subscriptionPath = config.SubscriptionPath
+	}
+	return e
+}
+
+// trusted tells whether the request comes from a trusted proxy
+func (e *endpointResolver) trusted(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return e.trustedIP(net.ParseIP(host))
+}
+
+// trustedIP tells whether the IP is the one of a trusted proxy
+func (e *endpointResolver) trustedIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range e.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// origin returns the scheme, host and path prefix the client sent the request
+// to, as reported by trusted proxies. The headers are read from the right, as
+// the values on their left may have been sent by the client.
+func (e *endpointResolver) origin(r *http.Request) (scheme string, host string, prefix string) {
+	scheme, host, prefix = "http", r.Host, e.pathPrefix
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if !e.trusted(r) {
+		return scheme, host, prefix
+	}
+
+	if proto := lastValue(r.Header.Get("X-Forwarded-Proto")); proto != "" {
+		scheme = strings.ToLower(proto)
+	}
+	if forwardedHost := lastValue(r.Header.Get("X-Forwarded-Host")); forwardedHost != "" {
+		host = forwardedHost
+	}
+	// Forwarded supersedes the X-Forwarded-* headers
+	forwarded := e.forwarded(r.Header.Get("Forwarded"))
+	if proto := forwarded["proto"]; proto != "" {
+		scheme = strings.ToLower(proto)
+	}
+	if forwardedHost := forwarded["host"]; forwardedHost != "" {
+		host = forwardedHost
+	}
+	if forwardedPrefix := lastValue(r.Header.Get("X-Forwarded-Prefix")); forwardedPrefix != "" {
+		prefix = strings.TrimSuffix(forwardedPrefix, "/") + prefix
+	}
+	return scheme, host, prefix
+}
+
+// forwarded returns the parameters of the element of a Forwarded header, as
+// defined by RFC 7239, describing the request of the client: walking the
+// elements from the right, the first one not forwarded by a trusted proxy
+func (e *endpointResolver) forwarded(header string) map[string]string {
+	elements := strings.Split(header, ",")
+	var params map[string]string
+	for i := len(elements) - 1; i >= 0; i-- {
+		params = parseForwardedElement(elements[i])
+		if !e.trustedIP(forwardedIP(params["for"])) {
+			break
+		}
+	}
+	return params
+}
+
+// endpoint returns the path of the GraphQL endpoint the client sent the
+// request to
+func (e *endpointResolver) endpoint(r *http.Request) string {
+	return e.path(r, r.URL.Path)
+}
+
+// path returns the path the client reaches the given path of the handler at
+func (e *endpointResolver) path(r *http.Request, p string) string {
+	_, _, prefix := e.origin(r)
+	return prefix + p
+}
+
+// subscriptionEndpoint returns the URL of the subscription endpoint, with the
+// ws or wss scheme matching the one the client sent the request with
+func (e *endpointResolver) subscriptionEndpoint(r *http.Request) string {
+	scheme, host, prefix := e.origin(r)
+	wsScheme := "ws"
+	if scheme == "https" || scheme == "wss" {
+		wsScheme = "wss"
+	}
+	return fmt.Sprintf("%v://%v%v%v", wsScheme, host, prefix, e.subscriptionPath)
+}
+
+// lastValue returns the last value of a comma separated header, the one set
+// by the proxy closest to the handler
+func lastValue(header string) string {
+	if i := strings.LastIndex(header, ","); i >= 0 {
+		header = header[i+1:]
+	}
+	return strings.TrimSpace(header)
+}
+
+// parseForwardedElement returns the parameters of an element of a Forwarded
+// header
+func parseForwardedElement(element string) map[string]string {
+	params := map[string]string{}
+	for _, pair := range strings.Split(element, ";") {
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(pair[:i]))
+		params[key] = strings.Trim(strings.TrimSpace(pair[i+1:]), `"`)
+	}
+	return params
+}
+
+// forwardedIP returns the IP of a Forwarded node, e.g. 192.0.2.60 or
+// "[2001:db8::1]:4711", nil for obfuscated and unknown nodes
+func forwardedIP(node string) net.IP {
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		node = host
+	}
+	return net.ParseIP(strings.Trim(node, "[]"))
+}
@@ -0,0 +1,123 @@
+package handler_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_Endpoints(t *testing.T) {
+	cases := map[string]struct {
+		config                       *handler.EndpointConfig
+		tls                          bool
+		remoteAddr                   string
+		headers                      map[string]string
+		expectedEndpoint             string
+		expectedSubscriptionEndpoint string
+	}{
+		"derives the endpoints from the request": {
+			expectedEndpoint:             "/graphql",
+			expectedSubscriptionEndpoint: "ws://example.com/subscriptions",
+		},
+		"uses wss over TLS": {
+			tls:                          true,
+			expectedEndpoint:             "/graphql",
+			expectedSubscriptionEndpoint: "wss://example.com/subscriptions",
+		},
+		"ignores the forwarding headers of untrusted proxies": {
+			config:     &handler.EndpointConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			remoteAddr: "192.0.2.1:1234",
+			headers: map[string]string{
+				"X-Forwarded-Proto":  "https",
+				"X-Forwarded-Host":   "api.example.org",
+				"X-Forwarded-Prefix": "/api",
+			},
+			expectedEndpoint:             "/graphql",
+			expectedSubscriptionEndpoint: "ws://example.com/subscriptions",
+		},
+		"honors the X-Forwarded headers of trusted proxies": {
+			config:     &handler.EndpointConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			remoteAddr: "10.1.2.3:1234",
+			headers: map[string]string{
+				"X-Forwarded-Proto":  "http, https",
+				"X-Forwarded-Host":   "spoofed.example.org, api.example.org",
+				"X-Forwarded-Prefix": "/spoofed, /api/",
+			},
+			expectedEndpoint:             "/api/graphql",
+			expectedSubscriptionEndpoint: "wss://api.example.org/api/subscriptions",
+		},
+		"honors the Forwarded header of trusted proxies": {
+			config:     &handler.EndpointConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			remoteAddr: "10.1.2.3:1234",
+			headers: map[string]string{
+				"X-Forwarded-Proto": "http",
+				"Forwarded":         `for=192.0.2.1;host=spoofed.example.org, for=192.0.2.60;proto=https;host="api.example.org", for="10.0.0.1:4711";proto=http;host=proxy.internal`,
+			},
+			expectedEndpoint:             "/graphql",
+			expectedSubscriptionEndpoint: "wss://api.example.org/subscriptions",
+		},
+		"prepends the mount prefix": {
+			config:                       &handler.EndpointConfig{PathPrefix: "/api/", SubscriptionPath: "/ws"},
+			expectedEndpoint:             "/api/graphql",
+			expectedSubscriptionEndpoint: "ws://example.com/api/ws",
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			for _, ide := range []string{"Playground", "GraphiQL"} {
+				req := httptest.NewRequest(http.MethodGet, "http://example.com/graphql", nil)
+				req.Header.Set("Accept", "text/html")
+				if tc.tls {
+					req.TLS = &tls.ConnectionState{}
+				}
+				if tc.remoteAddr != "" {
+					req.RemoteAddr = tc.remoteAddr
+				}
+				for k, v := range tc.headers {
+					req.Header.Set(k, v)
+				}
+
+				h := handler.New(&handler.Config{
+					Schema:     &testutil.StarWarsSchema,
+					GraphiQL:   ide == "GraphiQL",
+					Playground: ide == "Playground",
+					Endpoints:  tc.config,
+				})
+				rr := httptest.NewRecorder()
+				h.ServeHTTP(rr, req)
+
+				body := rr.Body.String()
+				expectedBodyContains := []string{`url: "` + tc.expectedSubscriptionEndpoint + `"`}
+				if ide == "Playground" {
+					expectedBodyContains = []string{
+						`endpoint: "` + tc.expectedEndpoint + `"`,
+						`subscriptionEndpoint: "` + tc.expectedSubscriptionEndpoint + `"`,
+					}
+				}
+				for _, e := range expectedBodyContains {
+					if !strings.Contains(body, e) {
+						t.Fatalf("%s: wrong body, expected %s to contain %s", ide, body, e)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_Endpoints_InvalidTrustedProxy(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected to panic, did not panic")
+		}
+	}()
+	handler.New(&handler.Config{
+		Schema:    &testutil.StarWarsSchema,
+		Endpoints: &handler.EndpointConfig{TrustedProxies: []string{"not an ip"}},
+	})
+}
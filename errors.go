@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Error codes set in the `extensions.code` field of the errors returned by the handler
+const (
+	ErrorCodeInternalServerError    = "INTERNAL_SERVER_ERROR"
+	ErrorCodeIntrospectionDisabled  = "INTROSPECTION_DISABLED"
+	ErrorCodeAliasLimitExceeded     = "ALIAS_LIMIT_EXCEEDED"
+	ErrorCodeRootFieldLimitExceeded = "ROOT_FIELD_LIMIT_EXCEEDED"
+	ErrorCodeDirectiveLimitExceeded = "DIRECTIVE_LIMIT_EXCEEDED"
+	ErrorCodeTokenLimitExceeded     = "TOKEN_LIMIT_EXCEEDED"
+	ErrorCodeRateLimited            = "RATE_LIMITED"
+	ErrorCodeQueryTooExpensive      = "QUERY_TOO_EXPENSIVE"
+	ErrorCodeUnauthenticated        = "UNAUTHENTICATED"
+	ErrorCodeForbidden              = "FORBIDDEN"
+)
+
+// ErrorFormatterFn allows a user to format each error of the result, with access
+// to the request context. The formatted error still carries the locations and
+// the path of the error, as well as its original error if any.
+// When set, it takes precedence over FormatErrorFn.
+type ErrorFormatterFn func(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError
+
+// formatErrorFnAdapter adapts a FormatErrorFn to an ErrorFormatterFn. Errors
+// without an original error are passed to formatErrorFn as is.
+func formatErrorFnAdapter(formatErrorFn func(err error) gqlerrors.FormattedError) ErrorFormatterFn {
+	return func(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError {
+		if originalError := err.OriginalError(); originalError != nil {
+			return formatErrorFn(originalError)
+		}
+		return formatErrorFn(err)
+	}
+}
+
+// formatErrors formats the given errors with the handler error formatter
+func (h *Handler) formatErrors(ctx context.Context, errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	if h.errorFormatter == nil || len(errs) == 0 {
+		return errs
+	}
+	formatted := make([]gqlerrors.FormattedError, len(errs))
+	for i, formattedError := range errs {
+		formatted[i] = h.errorFormatter(ctx, formattedError)
+	}
+	return formatted
+}
+
+// newCodedError returns a formatted error with the given message and
+// `extensions.code`
+func newCodedError(message string, code string) gqlerrors.FormattedError {
+	err := gqlerrors.NewFormattedError(message)
+	err.Extensions = map[string]interface{}{"code": code}
+	return err
+}
+
+// writeErrors writes a GraphQL response holding only the given errors
+func writeErrors(w http.ResponseWriter, statusCode int, errs ...gqlerrors.FormattedError) {
+	buff, _ := json.Marshal(&graphql.Result{Errors: errs})
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	w.Write(buff)
+}
@@ -0,0 +1,109 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_BasicQuery_WithErrorFormatter(t *testing.T) {
+	resolverError := customError{message: "resolver error"}
+	myNameQuery := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Name: "name",
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nil, resolverError
+				},
+			},
+		},
+	})
+	myNameSchema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: myNameQuery,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"name": nil,
+		},
+		Errors: []gqlerrors.FormattedError{
+			{
+				Message: "resolver error",
+				Locations: []location.SourceLocation{
+					{Line: 1, Column: 2},
+				},
+				Path: []interface{}{"name"},
+				Extensions: map[string]interface{}{
+					"code":    "CUSTOM",
+					"request": "request-id",
+				},
+			},
+		},
+	}
+
+	h := handler.New(&handler.Config{
+		Schema: &myNameSchema,
+		ErrorFormatter: func(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError {
+			if _, ok := err.OriginalError().(*gqlerrors.Error); !ok {
+				t.Fatalf("unexpected original error type: %v", reflect.TypeOf(err.OriginalError()))
+			}
+			err.Extensions = map[string]interface{}{
+				"code":    "CUSTOM",
+				"request": ctx.Value("request"),
+			}
+			return err
+		},
+	})
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/graphql?%v", `query={name}`), nil)
+	req = req.WithContext(context.WithValue(req.Context(), "request", "request-id"))
+	result, resp := executeTest(t, h, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestHandler_FormatErrorFn_WithoutOriginalError(t *testing.T) {
+	var received error
+	h := handler.New(&handler.Config{
+		Schema: &testutil.StarWarsSchema,
+		Middlewares: []handler.Middleware{
+			func(next handler.ExecuteFunc) handler.ExecuteFunc {
+				return func(ctx context.Context, params *graphql.Params) *graphql.Result {
+					return &graphql.Result{
+						Errors: []gqlerrors.FormattedError{{Message: "no original error"}},
+					}
+				}
+			},
+		},
+		FormatErrorFn: func(err error) gqlerrors.FormattedError {
+			received = err
+			return gqlerrors.FormatError(err)
+		},
+	})
+
+	req, _ := http.NewRequest("GET", "/graphql?query={hero{name}}", nil)
+	result, _ := executeTest(t, h, req)
+	if received == nil || received.Error() != "no original error" {
+		t.Fatalf("FormatErrorFn received the wrong error: %v", received)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Message != "no original error" {
+		t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+	}
+}
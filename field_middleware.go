@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+)
+
+// FieldMiddleware wraps the resolver of every field in the Schema. The
+// graphql.ResolveParams passed to the resolver carry the request context,
+// the field arguments, and through Info the field path and parent type.
+//
+// The field middlewares only run for the requests executed by the handler
+// they are configured on, so that several handlers can share a schema.
+type FieldMiddleware func(next graphql.FieldResolveFn) graphql.FieldResolveFn
+
+type fieldMiddlewaresContextKey struct{}
+
+// fieldMiddlewareChain composes the field middlewares of a handler around the
+// resolvers of the schema fields, once per field
+type fieldMiddlewareChain struct {
+	middlewares []FieldMiddleware
+	// resolvers are the composed resolvers by *graphql.FieldDefinition
+	resolvers sync.Map
+}
+
+// newFieldMiddlewareChain returns the chain of the given field middlewares,
+// the first middleware being the outermost one. It returns nil without
+// middlewares.
+func newFieldMiddlewareChain(schema *graphql.Schema, middlewares []FieldMiddleware) *fieldMiddlewareChain {
+	if len(middlewares) == 0 {
+		return nil
+	}
+	installFieldDispatch(schema)
+	return &fieldMiddlewareChain{middlewares: middlewares}
+}
+
+// withContext returns a copy of ctx holding the chain, so that the resolvers
+// run it
+func (c *fieldMiddlewareChain) withContext(ctx context.Context) context.Context {
+	if c == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, fieldMiddlewaresContextKey{}, c)
+}
+
+// resolver returns the resolver of the field wrapped by the middlewares
+func (c *fieldMiddlewareChain) resolver(field *graphql.FieldDefinition, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	if fn, ok := c.resolvers.Load(field); ok {
+		return fn.(graphql.FieldResolveFn)
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		resolve = c.middlewares[i](resolve)
+	}
+	fn, _ := c.resolvers.LoadOrStore(field, resolve)
+	return fn.(graphql.FieldResolveFn)
+}
+
+// installFieldDispatchMu serializes the installations of the field dispatch
+var installFieldDispatchMu sync.Mutex
+
+// fieldDispatcher is the resolver installed on a schema field, running the
+// field middlewares held by the context of the request around the original
+// resolver
+type fieldDispatcher struct {
+	field   *graphql.FieldDefinition
+	resolve graphql.FieldResolveFn
+}
+
+func (d *fieldDispatcher) dispatch(p graphql.ResolveParams) (interface{}, error) {
+	if p.Context != nil {
+		if chain, ok := p.Context.Value(fieldMiddlewaresContextKey{}).(*fieldMiddlewareChain); ok {
+			return chain.resolver(d.field, d.resolve)(p)
+		}
+	}
+	return d.resolve(p)
+}
+
+// fieldDispatchPointer is the code pointer shared by the dispatch method
+// values, which identifies the fields already wrapped
+var fieldDispatchPointer = reflect.ValueOf((&fieldDispatcher{}).dispatch).Pointer()
+
+// installFieldDispatch wraps the resolvers of all the object fields of the
+// schema, so that they run the field middlewares held by the context of the
+// request. Fields without a resolver are wrapped around
+// graphql.DefaultResolveFn.
+//
+// The schema is modified in place, but every field is only wrapped once, and
+// the resolvers are left unchanged for the requests without field middlewares.
+func installFieldDispatch(schema *graphql.Schema) {
+	installFieldDispatchMu.Lock()
+	defer installFieldDispatchMu.Unlock()
+
+	for name, t := range schema.TypeMap() {
+		// skip the introspection types
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		object, ok := t.(*graphql.Object)
+		if !ok {
+			continue
+		}
+		for _, field := range object.Fields() {
+			if field.Resolve != nil && reflect.ValueOf(field.Resolve).Pointer() == fieldDispatchPointer {
+				continue
+			}
+			resolve := field.Resolve
+			if resolve == nil {
+				resolve = graphql.DefaultResolveFn
+			}
+			field.Resolve = (&fieldDispatcher{field: field, resolve: resolve}).dispatch
+		}
+	}
+}
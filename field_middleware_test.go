@@ -0,0 +1,107 @@
+package handler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_FieldMiddlewares(t *testing.T) {
+	schema := newTestSchema(t)
+
+	var visited []string
+	h := handler.New(&handler.Config{
+		Schema: &schema,
+		FieldMiddlewares: []handler.FieldMiddleware{
+			func(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+				return func(p graphql.ResolveParams) (interface{}, error) {
+					path := fmt.Sprint(p.Info.Path.AsArray())
+					visited = append(visited, fmt.Sprintf("%s.%s%v", p.Info.ParentType.Name(), p.Info.FieldName, path))
+					if p.Info.ParentType.Name() == "User" && p.Info.FieldName == "secret" {
+						return nil, errors.New("forbidden")
+					}
+					return next(p)
+				}
+			},
+			func(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+				return func(p graphql.ResolveParams) (interface{}, error) {
+					value, err := next(p)
+					if s, ok := value.(string); ok {
+						return strings.ToUpper(s), err
+					}
+					return value, err
+				}
+			},
+		},
+	})
+
+	req, _ := http.NewRequest("GET", `/graphql?query={user(name:"leia"){name secret}}`, nil)
+	result, resp := executeTest(t, h, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+
+	expectedData := map[string]interface{}{
+		"user": map[string]interface{}{
+			"name":   "LEIA",
+			"secret": nil,
+		},
+	}
+	if !reflect.DeepEqual(result.Data, expectedData) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expectedData, result.Data))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Message != "forbidden" {
+		t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+	}
+
+	// sibling fields are not resolved in a deterministic order
+	sort.Strings(visited)
+	expectedVisited := []string{"Query.user[user]", "User.name[user name]", "User.secret[user secret]"}
+	if !reflect.DeepEqual(visited, expectedVisited) {
+		t.Fatalf("wrong visited fields, expected %v, got %v", expectedVisited, visited)
+	}
+}
+
+func TestHandler_FieldMiddlewares_SharedSchema(t *testing.T) {
+	schema := newTestSchema(t)
+
+	calls := 0
+	config := &handler.Config{
+		Schema: &schema,
+		FieldMiddlewares: []handler.FieldMiddleware{
+			func(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+				return func(p graphql.ResolveParams) (interface{}, error) {
+					calls++
+					return next(p)
+				}
+			},
+		},
+	}
+	handler.New(config)
+	h := handler.New(config)
+	plain := handler.New(&handler.Config{Schema: &schema})
+
+	req, _ := http.NewRequest("GET", `/graphql?query={user(name:"leia"){name}}`, nil)
+	if result, _ := executeTest(t, h, req); result.HasErrors() {
+		t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the middleware to wrap each resolver once, got %d calls", calls)
+	}
+
+	req, _ = http.NewRequest("GET", `/graphql?query={user(name:"leia"){name}}`, nil)
+	if result, _ := executeTest(t, plain, req); result.HasErrors() {
+		t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the middleware not to run for another handler, got %d calls", calls)
+	}
+}
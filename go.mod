@@ -1,5 +1,6 @@
 module github.com/graphql-go/handler
 
-go 1.14
+// go 1.16 is required by go:embed and io/fs, which self-host the IDE assets
+go 1.16
 
-require github.com/graphql-go/graphql v0.8.1 // indirect
+require github.com/graphql-go/graphql v0.8.1
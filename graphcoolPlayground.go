@@ -5,29 +5,116 @@ import (
 	"net/http"
 )
 
-type playgroundData struct {
+type PlaygroundConfig struct {
+	Endpoint             string
+	SubscriptionEndpoint string
+	// Settings are the settings of the editor, of the requests and of the
+	// schema polling
+	Settings *PlaygroundSettings
+	// Tabs are the tabs opened in the workspace
+	Tabs []PlaygroundTab
+	// Headers are the default HTTP headers of the tabs, e.g. an authorization
+	// header placeholder
+	Headers map[string]string
+	// WorkspaceName is the name of the workspace, which keeps the tabs apart
+	// from the ones of other endpoints
+	WorkspaceName string
+	// KeepTitle keeps the page title, instead of setting it to the endpoint
+	KeepTitle bool
+	// Template replaces the page template, it is executed with PlaygroundData
+	Template *template.Template
+	// Blocks override the "head", "title" and "logo" blocks of the page
+	// template
+	Blocks map[string]string
+}
+
+// PlaygroundSettings are the settings of Playground. Unset settings keep the
+// Playground defaults.
+type PlaygroundSettings struct {
+	// EditorTheme is "dark" or "light"
+	EditorTheme      string `json:"editor.theme,omitempty"`
+	EditorFontFamily string `json:"editor.fontFamily,omitempty"`
+	EditorFontSize   int    `json:"editor.fontSize,omitempty"`
+	// EditorCursorShape is "line", "block" or "underline"
+	EditorCursorShape  string `json:"editor.cursorShape,omitempty"`
+	EditorReuseHeaders *bool  `json:"editor.reuseHeaders,omitempty"`
+	// RequestCredentials is "omit", "include" or "same-origin"
+	RequestCredentials  string `json:"request.credentials,omitempty"`
+	HideTracingResponse *bool  `json:"tracing.hideTracingResponse,omitempty"`
+	PollingEnable       *bool  `json:"schema.polling.enable,omitempty"`
+	// PollingInterval is the interval of the schema polling in milliseconds
+	PollingInterval       int    `json:"schema.polling.interval,omitempty"`
+	PollingEndpointFilter string `json:"schema.polling.endpointFilter,omitempty"`
+	DisableComments       *bool  `json:"schema.disableComments,omitempty"`
+}
+
+// PlaygroundTab is a tab opened in Playground
+type PlaygroundTab struct {
+	// Endpoint defaults to the GraphQL endpoint
+	Endpoint  string            `json:"endpoint"`
+	Name      string            `json:"name,omitempty"`
+	Query     string            `json:"query"`
+	Variables string            `json:"variables,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+// PlaygroundData is the page data structure of the rendered Playground page.
+// The assets are referenced with the "stylesheet" and "script" templates, e.g.
+// {{ template "script" index .Assets "middleware.js" }}.
+type PlaygroundData struct {
 	PlaygroundVersion    string
 	Endpoint             string
 	SubscriptionEndpoint string
 	SetTitle             bool
+	Settings             *PlaygroundSettings
+	Tabs                 []PlaygroundTab
+	Headers              map[string]string
+	WorkspaceName        string
+	Nonce                string
+	Assets               map[string]AssetRef
 }
 
-// renderPlayground renders the Playground GUI
-func renderPlayground(w http.ResponseWriter, r *http.Request, endpoint string, subscriptionEndpoint string) {
-	t := template.New("Playground")
-	t, err := t.Parse(graphcoolPlaygroundTemplate)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+// newPlaygroundTemplate returns the page template of the Playground config
+func newPlaygroundTemplate(config *PlaygroundConfig) *template.Template {
+	if config == nil {
+		config = &PlaygroundConfig{}
+	}
+	if config.Template != nil {
+		return customIDETemplate(config.Template)
 	}
+	return parseIDETemplate("Playground", graphcoolPlaygroundTemplate, config.Blocks)
+}
 
-	d := playgroundData{
+// renderPlayground renders the Playground GUI
+func renderPlayground(w http.ResponseWriter, t *template.Template, ctx IDEContext, config *PlaygroundConfig, assets map[string]AssetRef) {
+	if config == nil {
+		config = &PlaygroundConfig{}
+	}
+	endpoint, subscriptionEndpoint := ctx.Endpoint, ctx.SubscriptionEndpoint
+	if config.Endpoint != "" {
+		endpoint = config.Endpoint
+	}
+	if config.SubscriptionEndpoint != "" {
+		subscriptionEndpoint = config.SubscriptionEndpoint
+	}
+	d := PlaygroundData{
 		PlaygroundVersion:    graphcoolPlaygroundVersion,
 		Endpoint:             endpoint,
 		SubscriptionEndpoint: subscriptionEndpoint,
-		SetTitle:             true,
+		SetTitle:             !config.KeepTitle,
+		Settings:             config.Settings,
+		Headers:              config.Headers,
+		WorkspaceName:        config.WorkspaceName,
+		Nonce:                ctx.Nonce,
+		Assets:               assets,
+	}
+	for _, tab := range config.Tabs {
+		if tab.Endpoint == "" {
+			tab.Endpoint = endpoint
+		}
+		d.Tabs = append(d.Tabs, tab)
 	}
-	err = t.ExecuteTemplate(w, "index", d)
+	err := t.Execute(w, d)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
@@ -51,15 +138,16 @@ add "&raw" to the end of the URL within a browser.
 <head>
   <meta charset=utf-8/>
   <meta name="viewport" content="user-scalable=no, initial-scale=1.0, minimum-scale=1.0, maximum-scale=1.0, minimal-ui">
-  <title>GraphQL Playground</title>
-  <link rel="stylesheet" href="//cdn.jsdelivr.net/npm/graphql-playground-react/build/static/css/index.css" />
-  <link rel="shortcut icon" href="//cdn.jsdelivr.net/npm/graphql-playground-react/build/favicon.png" />
-  <script src="//cdn.jsdelivr.net/npm/graphql-playground-react/build/static/js/middleware.js"></script>
+  <title>{{ block "title" . }}GraphQL Playground{{ end }}</title>
+  {{ template "stylesheet" index .Assets "index.css" }}
+  <link rel="shortcut icon" href="{{ (index .Assets "favicon.png").URL }}" />
+  {{ template "script" index .Assets "middleware.js" }}
+  {{ block "head" . }}{{ end }}
 </head>
 
 <body>
   <div id="root">
-    <style>
+    <style{{ with .Nonce }} nonce="{{ . }}"{{ end }}>
       body {
         background-color: rgb(23, 42, 58);
         font-family: Open Sans, sans-serif;
@@ -86,17 +174,29 @@ add "&raw" to the end of the URL within a browser.
         font-weight: 400;
       }
     </style>
-    <img src='//cdn.jsdelivr.net/npm/graphql-playground-react/build/logo.png' alt=''>
+    {{ block "logo" . }}<img src='{{ (index .Assets "logo.png").URL }}' alt=''>{{ end }}
     <div class="loading"> Loading
       <span class="title">GraphQL Playground</span>
     </div>
   </div>
-  <script>window.addEventListener('load', function (event) {
+  <script{{ with .Nonce }} nonce="{{ . }}"{{ end }}>window.addEventListener('load', function (event) {
       GraphQLPlayground.init(document.getElementById('root'), {
         // options as 'endpoint' belong here
         endpoint: {{ .Endpoint }},
         subscriptionEndpoint: {{ .SubscriptionEndpoint }},
-        setTitle: {{ .SetTitle }}
+        setTitle: {{ .SetTitle }},
+        {{- with .Settings }}
+        settings: {{ . }},
+        {{- end }}
+        {{- with .Tabs }}
+        tabs: {{ . }},
+        {{- end }}
+        {{- with .Headers }}
+        headers: {{ . }},
+        {{- end }}
+        {{- with .WorkspaceName }}
+        workspaceName: {{ . }},
+        {{- end }}
       })
     })</script>
 </body>
@@ -89,3 +89,89 @@ func TestRenderPlayground(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderPlayground_Config(t *testing.T) {
+	reuseHeaders := false
+	req, err := http.NewRequest(http.MethodGet, "/graphql", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Accept", "text/html")
+
+	h := handler.New(&handler.Config{
+		Schema:     &testutil.StarWarsSchema,
+		Playground: true,
+		PlaygroundConfig: &handler.PlaygroundConfig{
+			Settings: &handler.PlaygroundSettings{
+				EditorTheme:        "light",
+				EditorFontSize:     16,
+				EditorReuseHeaders: &reuseHeaders,
+				RequestCredentials: "include",
+				PollingInterval:    5000,
+			},
+			Tabs: []handler.PlaygroundTab{{
+				Name:  "Heroes",
+				Query: "{ hero { name } }",
+			}},
+			Headers:       map[string]string{"Authorization": "Bearer token"},
+			WorkspaceName: "</script><script>alert(1)</script>",
+			KeepTitle:     true,
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	expectedBodyContains := []string{
+		`setTitle:  false ,`,
+		`settings: {"editor.theme":"light","editor.fontSize":16,"editor.reuseHeaders":false,"request.credentials":"include","schema.polling.interval":5000},`,
+		`tabs: [{"endpoint":"/graphql","name":"Heroes","query":"{ hero { name } }"}],`,
+		`headers: {"Authorization":"Bearer token"},`,
+		`workspaceName: "\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e",`,
+	}
+	for _, e := range expectedBodyContains {
+		if !strings.Contains(body, e) {
+			t.Fatalf("wrong body, expected %s to contain %s", body, e)
+		}
+	}
+	if strings.Contains(body, "<script>alert(1)") {
+		t.Fatalf("wrong body, expected %s to escape the workspace name", body)
+	}
+}
+
+func TestRenderPlayground_Blocks(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/graphql", nil)
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Set("Accept", "text/html")
+
+	h := handler.New(&handler.Config{
+		Schema:     &testutil.StarWarsSchema,
+		Playground: true,
+		PlaygroundConfig: &handler.PlaygroundConfig{
+			Blocks: map[string]string{
+				"title": "Acme API",
+				"logo":  `<img src="/acme.png" alt="Acme">`,
+			},
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	expectedBodyContains := []string{
+		`<title>Acme API</title>`,
+		`<img src="/acme.png" alt="Acme">`,
+	}
+	for _, e := range expectedBodyContains {
+		if !strings.Contains(body, e) {
+			t.Fatalf("wrong body, expected %s to contain %s", body, e)
+		}
+	}
+	if strings.Contains(body, "logo.png") {
+		t.Fatalf("wrong body, expected %s to not contain the Playground logo", body)
+	}
+}
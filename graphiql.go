@@ -4,30 +4,91 @@ import (
 	"encoding/json"
 	"html/template"
 	"net/http"
-
-	"github.com/graphql-go/graphql"
 )
 
-// graphiqlData is the page data structure of the rendered GraphiQL page
-type graphiqlData struct {
+// GraphiQLConfig configures the GraphiQL page
+type GraphiQLConfig struct {
+	// Legacy renders GraphiQL 0.11, instead of the current GraphiQL with its
+	// explorer and history plugins
+	Legacy bool
+	// DefaultQuery is the query of the editor when no query is provided
+	DefaultQuery string
+	// DefaultHeaders are the headers of the headers editor, e.g. an
+	// authorization header placeholder
+	DefaultHeaders map[string]string
+	// Tabs are the tabs opened when no query is provided
+	Tabs []GraphiQLTab
+	// Theme is "light", "dark", or empty to follow the system theme
+	Theme string
+	// HeadersEditor shows the headers editor
+	HeadersEditor bool
+	// SubscriptionEndpoint is the graphql-ws endpoint subscriptions are sent
+	// to, defaults to the one derived with Config.Endpoints
+	SubscriptionEndpoint string
+	// Template replaces the page template, it is executed with GraphiQLData
+	Template *template.Template
+	// Blocks override the "head", "title" and "logo" blocks of the page
+	// template
+	Blocks map[string]string
+}
+
+// GraphiQLTab is a tab opened in GraphiQL
+type GraphiQLTab struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+	Headers   string `json:"headers,omitempty"`
+}
+
+// GraphiQLData is the page data structure of the rendered GraphiQL page. The
+// assets are referenced with the "stylesheet" and "script" templates, e.g.
+// {{ template "script" index .Assets "graphiql.min.js" }}.
+type GraphiQLData struct {
 	GraphiqlVersion string
+	Assets          map[string]AssetRef
+	Endpoint        string
 	QueryString     string
 	VariablesString string
 	OperationName   string
 	ResultString    string
+	DefaultQuery    string
+	HeadersString   string
+	Tabs            []GraphiQLTab
+	Theme           string
+	HeadersEditor   bool
+	SubscriptionURL string
+	Nonce           string
+}
+
+// newGraphiQLTemplate returns the page template of the GraphiQL config
+func newGraphiQLTemplate(config *GraphiQLConfig) *template.Template {
+	if config == nil {
+		config = &GraphiQLConfig{}
+	}
+	if config.Template != nil {
+		return customIDETemplate(config.Template)
+	}
+	if config.Legacy {
+		return parseIDETemplate("GraphiQL", graphiqlTemplate, config.Blocks)
+	}
+	return parseIDETemplate("GraphiQL", graphiqlModernTemplate, config.Blocks)
 }
 
 // renderGraphiQL renders the GraphiQL GUI
-func renderGraphiQL(w http.ResponseWriter, params graphql.Params) {
-	t := template.New("GraphiQL")
-	t, err := t.Parse(graphiqlTemplate)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+func renderGraphiQL(w http.ResponseWriter, t *template.Template, ctx IDEContext, config *GraphiQLConfig, assets map[string]AssetRef) {
+	if config == nil {
+		config = &GraphiQLConfig{}
+	}
+	subscriptionEndpoint := ctx.SubscriptionEndpoint
+	if config.SubscriptionEndpoint != "" {
+		subscriptionEndpoint = config.SubscriptionEndpoint
+	}
+	version := graphiqlModernVersion
+	if config.Legacy {
+		version = graphiqlVersion
 	}
 
 	// Create variables string
-	vars, err := json.MarshalIndent(params.VariableValues, "", "  ")
+	vars, err := json.MarshalIndent(ctx.Params.VariableValues, "", "  ")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -39,25 +100,43 @@ func renderGraphiQL(w http.ResponseWriter, params graphql.Params) {
 
 	// Create result string
 	var resString string
-	if params.RequestString == "" {
-		resString = ""
-	} else {
-		result, err := json.MarshalIndent(graphql.Do(params), "", "  ")
+	if ctx.Result != nil {
+		res, err := json.MarshalIndent(ctx.Result, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resString = string(res)
+	}
+
+	// Create default headers string
+	var headersString string
+	if len(config.DefaultHeaders) > 0 {
+		headers, err := json.MarshalIndent(config.DefaultHeaders, "", "  ")
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		resString = string(result)
+		headersString = string(headers)
 	}
 
-	d := graphiqlData{
-		GraphiqlVersion: graphiqlVersion,
-		QueryString:     params.RequestString,
+	d := GraphiQLData{
+		GraphiqlVersion: version,
+		Assets:          assets,
+		Endpoint:        ctx.Endpoint,
+		QueryString:     ctx.Params.RequestString,
 		ResultString:    resString,
 		VariablesString: varsString,
-		OperationName:   params.OperationName,
+		OperationName:   ctx.Params.OperationName,
+		DefaultQuery:    config.DefaultQuery,
+		HeadersString:   headersString,
+		Tabs:            config.Tabs,
+		Theme:           config.Theme,
+		HeadersEditor:   config.HeadersEditor,
+		SubscriptionURL: subscriptionEndpoint,
+		Nonce:           ctx.Nonce,
 	}
-	err = t.ExecuteTemplate(w, "index", d)
+	err = t.Execute(w, d)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
@@ -65,10 +144,10 @@ func renderGraphiQL(w http.ResponseWriter, params graphql.Params) {
 	return
 }
 
-// graphiqlVersion is the current version of GraphiQL
+// graphiqlVersion is the version of the legacy GraphiQL
 const graphiqlVersion = "0.11.11"
 
-// tmpl is the page template to render GraphiQL
+// graphiqlTemplate is the page template to render the legacy GraphiQL
 const graphiqlTemplate = `
 {{ define "index" }}
 <!--
@@ -83,10 +162,10 @@ add "&raw" to the end of the URL within a browser.
 <html>
 <head>
   <meta charset="utf-8" />
-  <title>GraphiQL</title>
+  <title>{{ block "title" . }}GraphiQL{{ end }}</title>
   <meta name="robots" content="noindex" />
   <meta name="referrer" content="origin">
-  <style>
+  <style{{ with .Nonce }} nonce="{{ . }}"{{ end }}>
     body {
       height: 100%;
       margin: 0;
@@ -97,16 +176,19 @@ add "&raw" to the end of the URL within a browser.
       height: 100vh;
     }
   </style>
-  <link href="//cdn.jsdelivr.net/npm/graphiql@{{ .GraphiqlVersion }}/graphiql.css" rel="stylesheet" />
-  <script src="//cdn.jsdelivr.net/es6-promise/4.0.5/es6-promise.auto.min.js"></script>
-  <script src="//cdn.jsdelivr.net/fetch/0.9.0/fetch.min.js"></script>
-  <script src="//cdn.jsdelivr.net/react/15.4.2/react.min.js"></script>
-  <script src="//cdn.jsdelivr.net/react/15.4.2/react-dom.min.js"></script>
-  <script src="//cdn.jsdelivr.net/npm/graphiql@{{ .GraphiqlVersion }}/graphiql.min.js"></script>
+  {{ template "stylesheet" index .Assets "graphiql.css" }}
+  {{ template "script" index .Assets "es6-promise.auto.min.js" }}
+  {{ template "script" index .Assets "fetch.min.js" }}
+  {{ template "script" index .Assets "react.min.js" }}
+  {{ template "script" index .Assets "react-dom.min.js" }}
+  {{ template "script" index .Assets "graphiql.min.js" }}
+  {{ template "script" index .Assets "graphql-ws.min.js" }}
+  {{ block "head" . }}{{ end }}
 </head>
 <body>
   <div id="graphiql">Loading...</div>
-  <script>
+  <template id="graphiql-logo">{{ block "logo" . }}{{ end }}</template>
+  <script{{ with .Nonce }} nonce="{{ . }}"{{ end }}>
     // Collect the URL parameters
     var parameters = {};
     window.location.search.substr(1).split('&').forEach(function (entry) {
@@ -140,10 +222,38 @@ add "&raw" to the end of the URL within a browser.
         otherParams[k] = parameters[k];
       }
     }
-    var fetchURL = locationQuery(otherParams);
+    var fetchURL = {{ .Endpoint }} + locationQuery(otherParams);
 
-    // Defines a GraphQL fetcher using the fetch API.
+    // Subscriptions are sent to the graphql-ws endpoint.
+    var wsClient = graphqlWs.createClient({
+      url: {{ .SubscriptionURL }},
+      lazy: true,
+    });
+
+    // Tells whether the operation to execute is a subscription.
+    function isSubscription(graphQLParams) {
+      var name = graphQLParams.operationName;
+      var pattern = name ?
+        new RegExp('\\bsubscription\\s+' + name + '\\b') :
+        new RegExp('^\\s*subscription\\b');
+      return pattern.test(graphQLParams.query);
+    }
+
+    // Defines a GraphQL fetcher using the fetch API, and graphql-ws for
+    // subscriptions.
     function graphQLFetcher(graphQLParams) {
+      if (isSubscription(graphQLParams)) {
+        return {
+          subscribe: function (observer) {
+            var unsubscribe = wsClient.subscribe(graphQLParams, {
+              next: function (value) { observer.next && observer.next(value); },
+              error: function (error) { observer.error && observer.error(error); },
+              complete: function () { observer.complete && observer.complete(); },
+            });
+            return { unsubscribe: unsubscribe };
+          }
+        };
+      }
       return fetch(fetchURL, {
         method: 'post',
         headers: {
@@ -184,6 +294,11 @@ add "&raw" to the end of the URL within a browser.
       history.replaceState(null, null, locationQuery(parameters));
     }
 
+    // Render the logo block, if any, as the GraphiQL logo.
+    var logoHTML = document.getElementById('graphiql-logo').innerHTML.trim();
+    var logo = logoHTML ? React.createElement(GraphiQL.Logo, null,
+      React.createElement('span', { dangerouslySetInnerHTML: { __html: logoHTML } })) : null;
+
     // Render <GraphiQL /> into the body.
     ReactDOM.render(
       React.createElement(GraphiQL, {
@@ -195,7 +310,7 @@ add "&raw" to the end of the URL within a browser.
         response: {{ .ResultString }},
         variables: {{ .VariablesString }},
         operationName: {{ .OperationName }},
-      }),
+      }, logo),
       document.getElementById('graphiql')
     );
   </script>
@@ -0,0 +1,169 @@
+package handler
+
+// graphiqlModernVersion is the current version of GraphiQL
+const graphiqlModernVersion = "3.1.1"
+
+// graphiqlExplorerVersion is the version of the GraphiQL explorer plugin
+const graphiqlExplorerVersion = "1.0.3"
+
+// graphqlWsVersion is the version of the graphql-ws client subscriptions
+// are sent with
+const graphqlWsVersion = "5.14.0"
+
+// reactVersion is the version of React the current GraphiQL runs on
+const reactVersion = "18.2.0"
+
+// graphiqlModernTemplate is the page template to render the current GraphiQL,
+// with its explorer plugin. The history plugin is built into GraphiQL.
+const graphiqlModernTemplate = `
+{{ define "index" }}
+<!--
+The request to this GraphQL server provided the header "Accept: text/html"
+and as a result has been presented GraphiQL - an in-browser IDE for
+exploring GraphQL.
+
+If you wish to receive JSON, provide the header "Accept: application/json" or
+add "&raw" to the end of the URL within a browser.
+-->
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>{{ block "title" . }}GraphiQL{{ end }}</title>
+  <meta name="robots" content="noindex" />
+  <meta name="referrer" content="origin">
+  <style{{ with .Nonce }} nonce="{{ . }}"{{ end }}>
+    body {
+      height: 100%;
+      margin: 0;
+      overflow: hidden;
+      width: 100%;
+    }
+    #graphiql {
+      height: 100vh;
+    }
+  </style>
+  {{ template "stylesheet" index .Assets "graphiql.min.css" }}
+  {{ template "stylesheet" index .Assets "plugin-explorer.css" }}
+  {{ template "script" index .Assets "react.production.min.js" }}
+  {{ template "script" index .Assets "react-dom.production.min.js" }}
+  {{ template "script" index .Assets "graphiql.min.js" }}
+  {{ template "script" index .Assets "plugin-explorer.umd.js" }}
+  {{ template "script" index .Assets "graphql-ws.min.js" }}
+  {{ block "head" . }}{{ end }}
+</head>
+<body>
+  <div id="graphiql">Loading...</div>
+  <template id="graphiql-logo">{{ block "logo" . }}{{ end }}</template>
+  <script{{ with .Nonce }} nonce="{{ . }}"{{ end }}>
+    // Collect the URL parameters
+    var parameters = {};
+    window.location.search.substr(1).split('&').forEach(function (entry) {
+      var eq = entry.indexOf('=');
+      if (eq >= 0) {
+        parameters[decodeURIComponent(entry.slice(0, eq))] =
+          decodeURIComponent(entry.slice(eq + 1));
+      }
+    });
+
+    // Produce a Location query string from a parameter object.
+    function locationQuery(params) {
+      return '?' + Object.keys(params).filter(function (key) {
+        return Boolean(params[key]);
+      }).map(function (key) {
+        return encodeURIComponent(key) + '=' +
+          encodeURIComponent(params[key]);
+      }).join('&');
+    }
+
+    // Derive a fetch URL from the current URL, sans the GraphQL parameters.
+    var graphqlParamNames = {
+      query: true,
+      variables: true,
+      operationName: true
+    };
+
+    var otherParams = {};
+    for (var k in parameters) {
+      if (parameters.hasOwnProperty(k) && graphqlParamNames[k] !== true) {
+        otherParams[k] = parameters[k];
+      }
+    }
+    var fetchURL = {{ .Endpoint }} + locationQuery(otherParams);
+
+    // When the query and variables string is edited, update the URL bar so
+    // that it can be easily shared.
+    function onEditQuery(newQuery) {
+      parameters.query = newQuery;
+      updateURL();
+    }
+
+    function onEditVariables(newVariables) {
+      parameters.variables = newVariables;
+      updateURL();
+    }
+
+    function onEditOperationName(newOperationName) {
+      parameters.operationName = newOperationName;
+      updateURL();
+    }
+
+    function updateURL() {
+      history.replaceState(null, null, locationQuery(parameters));
+    }
+
+    var theme = {{ .Theme }};
+    if (theme) {
+      localStorage.setItem('graphiql:theme', theme);
+    }
+
+    var props = {
+      fetcher: GraphiQL.createFetcher({
+        url: fetchURL,
+        // Subscriptions are sent to the graphql-ws endpoint.
+        wsClient: graphqlWs.createClient({
+          url: {{ .SubscriptionURL }},
+          lazy: true,
+        }),
+      }),
+      plugins: [GraphiQLPluginExplorer.explorerPlugin()],
+      onEditQuery: onEditQuery,
+      onEditVariables: onEditVariables,
+      onEditOperationName: onEditOperationName,
+      isHeadersEditorEnabled: {{ .HeadersEditor }},
+      defaultEditorToolsVisibility: true,
+    };
+    var query = {{ .QueryString }};
+    if (query) {
+      props.query = query;
+      props.variables = {{ .VariablesString }};
+      props.operationName = {{ .OperationName }};
+      props.response = {{ .ResultString }};
+    } else {
+      var defaultQuery = {{ .DefaultQuery }};
+      if (defaultQuery) {
+        props.defaultQuery = defaultQuery;
+      }
+      var tabs = {{ .Tabs }};
+      if (tabs && tabs.length) {
+        props.defaultTabs = tabs;
+      }
+    }
+    var headers = {{ .HeadersString }};
+    if (headers) {
+      props.defaultHeaders = headers;
+    }
+
+    // Render the logo block, if any, as the GraphiQL logo.
+    var logoHTML = document.getElementById('graphiql-logo').innerHTML.trim();
+    var logo = logoHTML ? React.createElement(GraphiQL.Logo, null,
+      React.createElement('span', { dangerouslySetInnerHTML: { __html: logoHTML } })) : null;
+
+    // Render <GraphiQL /> into the body.
+    var root = ReactDOM.createRoot(document.getElementById('graphiql'));
+    root.render(React.createElement(GraphiQL, props, logo));
+  </script>
+</body>
+</html>
+{{ end }}
+`
@@ -1,11 +1,15 @@
 package handler_test
 
 import (
+	"context"
+	"crypto/tls"
+	"html/template"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/graphql/testutil"
 	"github.com/graphql-go/handler"
 )
@@ -88,3 +92,251 @@ func TestRenderGraphiQL(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderGraphiQL_Config(t *testing.T) {
+	cases := map[string]struct {
+		config               *handler.GraphiQLConfig
+		expectedBodyContains []string
+	}{
+		"renders the current GraphiQL by default": {
+			expectedBodyContains: []string{
+				"graphiql@3.1.1/graphiql.min.js",
+				"react@18.2.0/umd/react.production.min.js",
+				"plugin-explorer@1.0.3/dist/index.umd.js",
+				"GraphiQLPluginExplorer.explorerPlugin()",
+				"ReactDOM.createRoot",
+			},
+		},
+		"renders the legacy GraphiQL": {
+			config: &handler.GraphiQLConfig{Legacy: true},
+			expectedBodyContains: []string{
+				"graphiql@0.11.11/graphiql.min.js",
+				"react/15.4.2/react.min.js",
+			},
+		},
+		"renders the GraphiQL config": {
+			config: &handler.GraphiQLConfig{
+				DefaultQuery:   "{ hero { name } }",
+				DefaultHeaders: map[string]string{"Authorization": "Bearer token"},
+				Tabs:           []handler.GraphiQLTab{{Query: "{ droid(id: \"2001\") { name } }"}},
+				Theme:          "dark",
+				HeadersEditor:  true,
+			},
+			expectedBodyContains: []string{
+				`var defaultQuery = "{ hero { name } }";`,
+				`var headers = "{\n  \"Authorization\": \"Bearer token\"\n}";`,
+				`var tabs = [{"query":"{ droid(id: \"2001\") { name } }"}];`,
+				`var theme = "dark";`,
+				`isHeadersEditorEnabled:  true`,
+			},
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "", nil)
+			if err != nil {
+				t.Error(err)
+			}
+			req.Header.Set("Accept", "text/html")
+
+			h := handler.New(&handler.Config{
+				Schema:         &testutil.StarWarsSchema,
+				GraphiQL:       true,
+				GraphiQLConfig: tc.config,
+			})
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			body := rr.Body.String()
+			for _, e := range tc.expectedBodyContains {
+				if !strings.Contains(body, e) {
+					t.Fatalf("wrong body, expected %s to contain %s", body, e)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderGraphiQL_SubscriptionEndpoint(t *testing.T) {
+	cases := map[string]struct {
+		config               *handler.GraphiQLConfig
+		tls                  bool
+		expectedBodyContains string
+	}{
+		"defaults to the requested host": {
+			expectedBodyContains: `url: "ws://example.com/subscriptions"`,
+		},
+		"defaults to wss over TLS": {
+			tls:                  true,
+			expectedBodyContains: `url: "wss://example.com/subscriptions"`,
+		},
+		"uses the configured endpoint": {
+			config:               &handler.GraphiQLConfig{SubscriptionEndpoint: "wss://ws.example.com/graphql"},
+			expectedBodyContains: `url: "wss://ws.example.com/graphql"`,
+		},
+		"uses the configured endpoint with the legacy GraphiQL": {
+			config:               &handler.GraphiQLConfig{Legacy: true, SubscriptionEndpoint: "wss://ws.example.com/graphql"},
+			expectedBodyContains: `url: "wss://ws.example.com/graphql"`,
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/graphql", nil)
+			if tc.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			req.Header.Set("Accept", "text/html")
+
+			h := handler.New(&handler.Config{
+				Schema:         &testutil.StarWarsSchema,
+				GraphiQL:       true,
+				GraphiQLConfig: tc.config,
+			})
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			body := rr.Body.String()
+			if !strings.Contains(body, tc.expectedBodyContains) {
+				t.Fatalf("wrong body, expected %s to contain %s", body, tc.expectedBodyContains)
+			}
+			if !strings.Contains(body, "graphql-ws@5.14.0/umd/graphql-ws.min.js") {
+				t.Fatalf("wrong body, expected %s to load graphql-ws", body)
+			}
+		})
+	}
+}
+
+func TestRenderGraphiQL_ExecutesOnce(t *testing.T) {
+	cases := map[string]struct {
+		url                string
+		prefillOnly        bool
+		expectedExecutions int
+	}{
+		"executes the query once": {
+			url:                "?query={hero{name}}",
+			expectedExecutions: 1,
+		},
+		"does not execute without query": {
+			expectedExecutions: 0,
+		},
+		"does not execute in prefill only mode": {
+			url:                "?query={hero{name}}",
+			prefillOnly:        true,
+			expectedExecutions: 0,
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			executions := 0
+			h := handler.New(&handler.Config{
+				Schema:   &testutil.StarWarsSchema,
+				GraphiQL: true,
+				IDE:      &handler.IDEConfig{PrefillOnly: tc.prefillOnly},
+				Middlewares: []handler.Middleware{
+					func(next handler.ExecuteFunc) handler.ExecuteFunc {
+						return func(ctx context.Context, params *graphql.Params) *graphql.Result {
+							executions++
+							return next(ctx, params)
+						}
+					},
+				},
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/graphql"+tc.url, nil)
+			req.Header.Set("Accept", "text/html")
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if executions != tc.expectedExecutions {
+				t.Fatalf("wrong executions, expected %v, got %v", tc.expectedExecutions, executions)
+			}
+			body := rr.Body.String()
+			hasResult := strings.Contains(body, `R2-D2`)
+			if hasResult != (tc.expectedExecutions > 0) {
+				t.Fatalf("wrong body, expected result %v in %s", tc.expectedExecutions > 0, body)
+			}
+			if tc.url != "" && !strings.Contains(body, `var query = "{hero{name}}";`) {
+				t.Fatalf("wrong body, expected %s to be prefilled with the query", body)
+			}
+		})
+	}
+}
+
+func TestRenderGraphiQL_Templates(t *testing.T) {
+	cases := map[string]struct {
+		config               *handler.GraphiQLConfig
+		expectedBodyContains []string
+	}{
+		"overrides the blocks": {
+			config: &handler.GraphiQLConfig{
+				Blocks: map[string]string{
+					"title": "Acme API",
+					"head":  `<link rel="icon" href="/acme.png" />`,
+					"logo":  `<b>Acme</b>`,
+				},
+			},
+			expectedBodyContains: []string{
+				`<title>Acme API</title>`,
+				`<link rel="icon" href="/acme.png" />`,
+				`<template id="graphiql-logo"><b>Acme</b></template>`,
+			},
+		},
+		"overrides the blocks of the legacy GraphiQL": {
+			config: &handler.GraphiQLConfig{
+				Legacy: true,
+				Blocks: map[string]string{"title": "{{ .GraphiqlVersion }}"},
+			},
+			expectedBodyContains: []string{`<title>0.11.11</title>`},
+		},
+		"replaces the template": {
+			config: &handler.GraphiQLConfig{
+				Template: template.Must(template.New("custom").Parse(
+					`<h1>{{ .QueryString }}</h1>{{ template "script" index .Assets "graphiql.min.js" }}`,
+				)),
+			},
+			expectedBodyContains: []string{
+				`<h1>{hero{name}}</h1><script src="//cdn.jsdelivr.net/npm/graphiql@3.1.1/graphiql.min.js"></script>`,
+			},
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			h := handler.New(&handler.Config{
+				Schema:         &testutil.StarWarsSchema,
+				GraphiQL:       true,
+				GraphiQLConfig: tc.config,
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/graphql?query={hero{name}}", nil)
+			req.Header.Set("Accept", "text/html")
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			body := rr.Body.String()
+			for _, e := range tc.expectedBodyContains {
+				if !strings.Contains(body, e) {
+					t.Fatalf("wrong body, expected %s to contain %s", body, e)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderGraphiQL_InvalidBlock(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected to panic, did not panic")
+		}
+	}()
+	handler.New(&handler.Config{
+		Schema:         &testutil.StarWarsSchema,
+		GraphiQL:       true,
+		GraphiQLConfig: &handler.GraphiQLConfig{Blocks: map[string]string{"title": "{{ .Title"}},
+	})
+}
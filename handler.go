@@ -2,7 +2,7 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -21,17 +21,35 @@ const (
 	ContentTypeFormURLEncoded = "application/x-www-form-urlencoded"
 )
 
+// ResultCallbackFn is called with the result of every executed request and
+// its response body. When the response is streamed, the response body is nil
+// unless Config.ResultCallbackBody is set.
 type ResultCallbackFn func(ctx context.Context, params *graphql.Params, result *graphql.Result, responseBody []byte)
 
 type Handler struct {
-	Schema           *graphql.Schema
-	pretty           bool
-	graphiql         bool
-	playground       bool
-	playgroundConfig *PlaygroundConfig
-	rootObjectFn     RootObjectFn
-	resultCallbackFn ResultCallbackFn
-	formatErrorFn    func(err error) gqlerrors.FormattedError
+	Schema                *graphql.Schema
+	pretty                bool
+	prettyIndent          string
+	ides                  *ideSet
+	rootObjectFn          RootObjectFn
+	resultCallbackFn      ResultCallbackFn
+	errorFormatter        ErrorFormatterFn
+	execute               ExecuteFunc
+	fieldMiddlewares      *fieldMiddlewareChain
+	panicHandler          PanicHandlerFn
+	introspection         IntrospectionFn
+	validationRules       []validationRuleFn
+	maxTokens             int
+	rateLimitConfig       *RateLimitConfig
+	authenticateFn        AuthenticateFn
+	authenticateChallenge string
+	cacheControl          bool
+	responseCache         *ResponseCacheConfig
+	compression           *CompressionConfig
+	maxDecompressedSize   int64
+	streamResponse        bool
+	resultCallbackBody    bool
+	endpoints             *endpointResolver
 }
 
 type RequestOptions struct {
@@ -65,8 +83,14 @@ func getFromForm(values url.Values) *RequestOptions {
 	return nil
 }
 
-// RequestOptions Parses a http.Request into GraphQL request options struct
+// RequestOptions Parses a http.Request into GraphQL request options struct.
+// Request bodies compressed with gzip or deflate are decompressed, up to
+// DefaultMaxDecompressedSize bytes.
 func NewRequestOptions(r *http.Request) *RequestOptions {
+	return newRequestOptions(r, DefaultMaxDecompressedSize)
+}
+
+func newRequestOptions(r *http.Request, maxDecompressedSize int64) *RequestOptions {
 	if reqOpt := getFromForm(r.URL.Query()); reqOpt != nil {
 		return reqOpt
 	}
@@ -79,6 +103,10 @@ func NewRequestOptions(r *http.Request) *RequestOptions {
 		return &RequestOptions{}
 	}
 
+	if err := decompressBody(r, maxDecompressedSize); err != nil {
+		return &RequestOptions{}
+	}
+
 	// TODO: improve Content-Type handling
 	contentTypeStr := r.Header.Get("Content-Type")
 	contentTypeTokens := strings.Split(contentTypeStr, ";")
@@ -127,8 +155,43 @@ func NewRequestOptions(r *http.Request) *RequestOptions {
 // ContextHandler provides an entrypoint into executing graphQL queries with a
 // user-provided context.
 func (h *Handler) ContextHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	// recover from panics raised while serving the request
+	pw := &panicResponseWriter{ResponseWriter: w}
+	defer h.recoverPanic(pw, r)
+	w = pw
+
+	if h.ides.servesAssets(r) {
+		h.ides.assets.ServeHTTP(w, r)
+		return
+	}
+
+	if h.authenticateFn != nil {
+		var ok bool
+		if ctx, ok = h.authenticate(ctx, w, r); !ok {
+			return
+		}
+		r = r.WithContext(ctx)
+	}
+
 	// get query
-	opts := NewRequestOptions(r)
+	opts := newRequestOptions(r, h.maxDecompressedSize)
+
+	if h.rateLimitConfig != nil && !h.rateLimit(w, r, opts) {
+		return
+	}
+
+	// decide before the execution whether an IDE is rendered, so that the
+	// query is executed at most once
+	renderer := h.ides.renderer(r)
+	renderIDE := renderer != nil && wantsIDE(r) && h.introspectionAllowed(ctx)
+
+	var cachePolicy *cachePolicy
+	if h.cacheControl {
+		ctx, cachePolicy = withCachePolicy(ctx, r)
+	}
+	if h.responseCache != nil {
+		ctx = withResponseCacheVary(ctx, r, h.responseCache.VaryFn)
+	}
 
 	// execute graphql query
 	params := graphql.Params{
@@ -141,56 +204,59 @@ func (h *Handler) ContextHandler(ctx context.Context, w http.ResponseWriter, r *
 	if h.rootObjectFn != nil {
 		params.RootObject = h.rootObjectFn(ctx, r)
 	}
-	result := graphql.Do(params)
-
-	if formatErrorFn := h.formatErrorFn; formatErrorFn != nil && len(result.Errors) > 0 {
-		formatted := make([]gqlerrors.FormattedError, len(result.Errors))
-		for i, formattedError := range result.Errors {
-			formatted[i] = formatErrorFn(formattedError.OriginalError())
+	// the IDE is only prefilled with requests without query, and with all
+	// of them in prefill only mode
+	var result *graphql.Result
+	if !renderIDE || (opts.Query != "" && !h.ides.prefillOnly) {
+		execute := h.execute
+		if execute == nil {
+			// a Handler literal has no middlewares
+			execute = h.do
 		}
-		result.Errors = formatted
+		result = execute(ctx, &params)
+		result.Errors = h.formatErrors(ctx, result.Errors)
 	}
 
-	if h.graphiql {
-		acceptHeader := r.Header.Get("Accept")
-		_, raw := r.URL.Query()["raw"]
-		if !raw && !strings.Contains(acceptHeader, "application/json") && strings.Contains(acceptHeader, "text/html") {
-			renderGraphiQL(w, params)
-			return
-		}
-	}
-
-	if h.playground {
-		acceptHeader := r.Header.Get("Accept")
-		_, raw := r.URL.Query()["raw"]
-		if !raw && !strings.Contains(acceptHeader, "application/json") && strings.Contains(acceptHeader, "text/html") {
-
-			endpoint := r.URL.Path
-			subscriptionEndpoint := fmt.Sprintf("ws://%v/subscriptions", r.Host)
-			if h.playgroundConfig != nil {
-				endpoint = h.playgroundConfig.Endpoint
-				subscriptionEndpoint = h.playgroundConfig.SubscriptionEndpoint
-			}
-
-			renderPlayground(w, r, endpoint, subscriptionEndpoint)
-			return
-		}
+	if renderIDE {
+		renderer.Render(w, r, IDEContext{
+			Params:               params,
+			Result:               result,
+			Endpoint:             h.endpoints.endpoint(r),
+			SubscriptionEndpoint: h.endpoints.subscriptionEndpoint(r),
+			Nonce:                h.ides.csp.apply(w),
+		})
+		return
 	}
 
 	// use proper JSON Header
 	w.Header().Add("Content-Type", "application/json; charset=utf-8")
 
+	pretty := h.isPretty(r)
 	var buff []byte
-	if h.pretty {
-		w.WriteHeader(http.StatusOK)
-		buff, _ = json.MarshalIndent(result, "", "\t")
-
-		w.Write(buff)
+	if h.streamResponse {
+		buff = h.streamResult(w, r, cachePolicy, result, pretty)
 	} else {
-		w.WriteHeader(http.StatusOK)
-		buff, _ = json.Marshal(result)
+		if pretty {
+			buff, _ = json.MarshalIndent(result, "", h.prettyIndent)
+		} else {
+			buff, _ = json.Marshal(result)
+		}
 
-		w.Write(buff)
+		var encoding string
+		if h.compression != nil {
+			encoding = h.compression.encoding(r, len(buff))
+		}
+		if cachePolicy != nil && writeCacheHeaders(w, r, cachePolicy, result, buff, encoding) {
+			if h.compression != nil {
+				w.Header().Add("Vary", "Accept-Encoding")
+			}
+			w.WriteHeader(http.StatusNotModified)
+		} else if h.compression != nil {
+			h.compression.writeCompressed(w, r, http.StatusOK, buff)
+		} else {
+			w.WriteHeader(http.StatusOK)
+			w.Write(buff)
+		}
 	}
 
 	if h.resultCallbackFn != nil {
@@ -206,26 +272,45 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // RootObjectFn allows a user to generate a RootObject per request
 type RootObjectFn func(ctx context.Context, r *http.Request) map[string]interface{}
 
-type PlaygroundConfig struct {
-	Endpoint             string
-	SubscriptionEndpoint string
-}
-
 type Config struct {
-	Schema           *graphql.Schema
-	Pretty           bool
-	GraphiQL         bool
-	Playground       bool
-	PlaygroundConfig *PlaygroundConfig
-	RootObjectFn     RootObjectFn
-	ResultCallbackFn ResultCallbackFn
-	FormatErrorFn    func(err error) gqlerrors.FormattedError
+	Schema                *graphql.Schema
+	Pretty                bool
+	PrettyIndent          string
+	GraphiQL              bool
+	GraphiQLConfig        *GraphiQLConfig
+	Playground            bool
+	PlaygroundConfig      *PlaygroundConfig
+	RootObjectFn          RootObjectFn
+	ResultCallbackFn      ResultCallbackFn
+	FormatErrorFn         func(err error) gqlerrors.FormattedError
+	ErrorFormatter        ErrorFormatterFn
+	MaskErrors            bool
+	MaskedErrorFn         MaskedErrorFn
+	Middlewares           []Middleware
+	FieldMiddlewares      []FieldMiddleware
+	PanicHandler          PanicHandlerFn
+	Introspection         IntrospectionFn
+	QueryLimits           *QueryLimits
+	RateLimit             *RateLimitConfig
+	Authenticate          AuthenticateFn
+	AuthenticateChallenge string
+	Authorization         *AuthorizationConfig
+	CacheControl          *CacheControlConfig
+	ResponseCache         *ResponseCacheConfig
+	Compression           *CompressionConfig
+	StreamResponse        bool
+	ResultCallbackBody    bool
+	AssetsPath            string
+	AssetsFS              fs.FS
+	Endpoints             *EndpointConfig
+	IDE                   *IDEConfig
+	ContentSecurityPolicy *ContentSecurityPolicyConfig
 }
 
 func NewConfig() *Config {
 	return &Config{
 		Schema:           nil,
-		Pretty:           true,
+		Pretty:           false,
 		GraphiQL:         true,
 		Playground:       false,
 		PlaygroundConfig: nil,
@@ -240,15 +325,80 @@ func New(p *Config) *Handler {
 	if p.Schema == nil {
 		panic("undefined GraphQL schema")
 	}
+	if p.RateLimit != nil && p.RateLimit.Limiter == nil {
+		panic("undefined rate limiter")
+	}
+
+	fieldMiddlewares := p.FieldMiddlewares
+	if p.Authorization != nil && !p.Authorization.FailOperation {
+		fieldMiddlewares = append([]FieldMiddleware{authorizationFieldMiddleware(p.Authorization.Rules)}, fieldMiddlewares...)
+	}
+	if p.CacheControl != nil {
+		fieldMiddlewares = append([]FieldMiddleware{cacheControlFieldMiddleware(*p.CacheControl)}, fieldMiddlewares...)
+	}
+
+	errorFormatter := p.ErrorFormatter
+	if errorFormatter == nil && p.FormatErrorFn != nil {
+		errorFormatter = formatErrorFnAdapter(p.FormatErrorFn)
+	}
+	if p.MaskErrors {
+		errorFormatter = maskErrors(errorFormatter, p.MaskedErrorFn)
+	}
 
-	return &Handler{
-		Schema:           p.Schema,
-		pretty:           p.Pretty,
-		graphiql:         p.GraphiQL,
-		playground:       p.Playground,
-		playgroundConfig: p.PlaygroundConfig,
-		rootObjectFn:     p.RootObjectFn,
-		resultCallbackFn: p.ResultCallbackFn,
-		formatErrorFn:    p.FormatErrorFn,
+	var validationRules []validationRuleFn
+	var maxTokens int
+	if p.QueryLimits != nil {
+		validationRules = append(validationRules, queryLimitsRule(*p.QueryLimits))
+		maxTokens = p.QueryLimits.MaxTokens
+	}
+	if p.Introspection != nil {
+		validationRules = append(validationRules, introspectionRule(p.Introspection))
+	}
+	if p.Authorization != nil && p.Authorization.FailOperation {
+		validationRules = append(validationRules, authorizationRule(p.Schema, p.Authorization.Rules))
 	}
+
+	authenticateChallenge := p.AuthenticateChallenge
+	if authenticateChallenge == "" {
+		authenticateChallenge = defaultAuthenticateChallenge
+	}
+
+	prettyIndent := p.PrettyIndent
+	if prettyIndent == "" {
+		prettyIndent = defaultPrettyIndent
+	}
+
+	var maxDecompressedSize int64 = DefaultMaxDecompressedSize
+	if p.Compression != nil && p.Compression.MaxDecompressedSize > 0 {
+		maxDecompressedSize = p.Compression.MaxDecompressedSize
+	}
+
+	endpoints := newEndpointResolver(p.Endpoints)
+	h := &Handler{
+		Schema:                p.Schema,
+		pretty:                p.Pretty,
+		prettyIndent:          prettyIndent,
+		ides:                  newIDESet(p, endpoints),
+		rootObjectFn:          p.RootObjectFn,
+		resultCallbackFn:      p.ResultCallbackFn,
+		errorFormatter:        errorFormatter,
+		panicHandler:          p.PanicHandler,
+		introspection:         p.Introspection,
+		validationRules:       validationRules,
+		maxTokens:             maxTokens,
+		rateLimitConfig:       p.RateLimit,
+		authenticateFn:        p.Authenticate,
+		authenticateChallenge: authenticateChallenge,
+		cacheControl:          p.CacheControl != nil,
+		responseCache:         p.ResponseCache,
+		fieldMiddlewares:      newFieldMiddlewareChain(p.Schema, fieldMiddlewares),
+		compression:           p.Compression,
+		maxDecompressedSize:   maxDecompressedSize,
+		streamResponse:        p.StreamResponse,
+		resultCallbackBody:    p.ResultCallbackBody,
+		endpoints:             endpoints,
+	}
+	h.execute = chainMiddlewares(h.do, p.Middlewares)
+
+	return h
 }
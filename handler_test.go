@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -42,6 +43,103 @@ func executeTest(t *testing.T, h *handler.Handler, req *http.Request) (*graphql.
 	return result, resp
 }
 
+// newTestSchema returns a new schema with users, accounts, products and
+// failing fields, shared by the tests which need a schema of their own
+func newTestSchema(t *testing.T) graphql.Schema {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"name":   &graphql.Field{Type: graphql.String},
+			"secret": &graphql.Field{Type: graphql.String},
+		},
+	})
+	billingType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Billing",
+		Fields: graphql.Fields{
+			"iban": &graphql.Field{Type: graphql.String},
+		},
+	})
+	accountType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Account",
+		Fields: graphql.Fields{
+			"name":    &graphql.Field{Type: graphql.String},
+			"email":   &graphql.Field{Type: graphql.String},
+			"billing": &graphql.Field{Type: billingType},
+		},
+	})
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"name":  &graphql.Field{Type: graphql.String},
+			"price": &graphql.Field{Type: graphql.Int},
+		},
+	})
+	resolveProduct := func(p graphql.ResolveParams) (interface{}, error) {
+		return map[string]interface{}{"name": "X-Wing", "price": 100}, nil
+	}
+	errorField := func(err error) *graphql.Field {
+		return &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return nil, err
+			},
+		}
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return map[string]interface{}{
+						"name":   p.Args["name"],
+						"secret": "s3cr3t",
+					}, nil
+				},
+			},
+			"account": &graphql.Field{
+				Type: accountType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return map[string]interface{}{
+						"name":    "luke",
+						"email":   "luke@rebellion.org",
+						"billing": map[string]interface{}{"iban": "XX00"},
+					}, nil
+				},
+			},
+			"product": &graphql.Field{Type: productType, Resolve: resolveProduct},
+			"cart": &graphql.Field{
+				Type: graphql.NewList(productType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return []interface{}{}, nil
+				},
+			},
+			"uncacheable": &graphql.Field{Type: graphql.String},
+			"unexpected":  errorField(errors.New("pq: connection refused")),
+			"safe":        errorField(safeError{message: "invalid input"}),
+			"extended":    errorField(extendedError{message: "not found"}),
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"buy": &graphql.Field{Type: productType, Resolve: resolveProduct},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
 func TestContextPropagated(t *testing.T) {
 	myNameQuery := graphql.NewObject(graphql.ObjectConfig{
 		Name: "Query",
@@ -400,3 +498,21 @@ func TestPlaygroundWithCustomConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestHandler_Literal(t *testing.T) {
+	h := &handler.Handler{Schema: &testutil.StarWarsSchema}
+
+	for _, accept := range []string{"application/json", "text/html"} {
+		req, _ := http.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{hero{name}}"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", accept)
+		result, resp := executeTest(t, h, req)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("%s: unexpected server response %v", accept, resp.Code)
+		}
+		expected := map[string]interface{}{"hero": map[string]interface{}{"name": "R2-D2"}}
+		if !reflect.DeepEqual(result.Data, expected) {
+			t.Fatalf("%s: wrong result, graphql result diff: %v", accept, testutil.Diff(expected, result.Data))
+		}
+	}
+}
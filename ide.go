@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Names of the built-in IDEs
+const (
+	IDEGraphiQL      = "graphiql"
+	IDEPlayground    = "playground"
+	IDEAltair        = "altair"
+	IDEApolloSandbox = "sandbox"
+	IDEVoyager       = "voyager"
+)
+
+// IDEQueryParam is the query parameter choosing the IDE rendered, when
+// IDEConfig.Selectable is set
+const IDEQueryParam = "ide"
+
+// IDEContext is what an IDE is rendered with
+type IDEContext struct {
+	// Params are the params of the request, which prefill the IDE
+	Params graphql.Params
+	// Result is the result of the request, nil when the request has no query
+	// or with IDEConfig.PrefillOnly
+	Result *graphql.Result
+	// Endpoint is the path of the GraphQL endpoint
+	Endpoint string
+	// SubscriptionEndpoint is the URL of the subscription endpoint
+	SubscriptionEndpoint string
+	// Nonce is the nonce of the inline scripts and styles, allowed by the
+	// Content-Security-Policy of the response. It is empty without
+	// Config.ContentSecurityPolicy.
+	Nonce string
+}
+
+// IDERenderer renders an in-browser IDE
+type IDERenderer interface {
+	Render(w http.ResponseWriter, r *http.Request, ctx IDEContext)
+}
+
+// IDERendererFn is an IDERenderer function
+type IDERendererFn func(w http.ResponseWriter, r *http.Request, ctx IDEContext)
+
+// Render renders the IDE
+func (fn IDERendererFn) Render(w http.ResponseWriter, r *http.Request, ctx IDEContext) {
+	fn(w, r, ctx)
+}
+
+// IDEConfig configures the IDEs rendered to browsers
+type IDEConfig struct {
+	// Default is the name of the IDE rendered, a built-in one or one of
+	// Renderers. It defaults to GraphiQL when Config.GraphiQL is set, and to
+	// Playground when Config.Playground is set.
+	Default string
+	// Selectable allows browsers to choose any built-in IDE, or one of
+	// Renderers, with the ide query parameter
+	Selectable bool
+	// Renderers are custom IDEs by name, they replace the built-in IDEs of
+	// the same name
+	Renderers map[string]IDERenderer
+	// PrefillOnly prefills the IDE with the query of the request without
+	// executing it, so that visiting a link never runs a mutation
+	PrefillOnly bool
+}
+
+// builtinIDE is an IDE shipped with the handler
+type builtinIDE struct {
+	manifest map[string]asset
+	// cdnOnly loads the assets from the CDN even when the assets are
+	// self-hosted, for the IDEs whose assets are not versioned
+	cdnOnly bool
+	// csp are the sources the IDE needs besides its assets
+	csp    map[string][]string
+	render func(w http.ResponseWriter, r *http.Request, ctx IDEContext, assets map[string]AssetRef)
+}
+
+// builtinIDEs returns the built-in IDEs, configured by the handler config.
+// Their templates are parsed once, it panics on invalid custom templates.
+func builtinIDEs(p *Config) map[string]builtinIDE {
+	graphiqlManifest := graphiqlModernAssets
+	if p.GraphiQLConfig != nil && p.GraphiQLConfig.Legacy {
+		graphiqlManifest = graphiqlAssets
+	}
+	graphiqlPage := newGraphiQLTemplate(p.GraphiQLConfig)
+	playgroundPage := newPlaygroundTemplate(p.PlaygroundConfig)
+	altairPage := parseIDETemplate("Altair", altairTemplate, nil)
+	apolloSandboxPage := parseIDETemplate("ApolloSandbox", apolloSandboxTemplate, nil)
+	voyagerPage := parseIDETemplate("Voyager", voyagerTemplate, nil)
+	return map[string]builtinIDE{
+		IDEGraphiQL: {
+			manifest: graphiqlManifest,
+			render: func(w http.ResponseWriter, r *http.Request, ctx IDEContext, assets map[string]AssetRef) {
+				renderGraphiQL(w, graphiqlPage, ctx, p.GraphiQLConfig, assets)
+			},
+		},
+		IDEPlayground: {
+			manifest: playgroundAssets,
+			render: func(w http.ResponseWriter, r *http.Request, ctx IDEContext, assets map[string]AssetRef) {
+				renderPlayground(w, playgroundPage, ctx, p.PlaygroundConfig, assets)
+			},
+		},
+		IDEAltair: {
+			manifest: altairAssets,
+			render: func(w http.ResponseWriter, r *http.Request, ctx IDEContext, assets map[string]AssetRef) {
+				renderAltair(w, altairPage, ctx, assets)
+			},
+		},
+		IDEApolloSandbox: {
+			manifest: apolloSandboxAssets,
+			cdnOnly:  true,
+			csp: map[string][]string{
+				"frame-src": {"https://sandbox.embed.apollographql.com"},
+			},
+			render: func(w http.ResponseWriter, r *http.Request, ctx IDEContext, assets map[string]AssetRef) {
+				renderApolloSandbox(w, apolloSandboxPage, ctx, assets)
+			},
+		},
+		IDEVoyager: {
+			manifest: voyagerAssets,
+			render: func(w http.ResponseWriter, r *http.Request, ctx IDEContext, assets map[string]AssetRef) {
+				renderVoyager(w, voyagerPage, ctx, assets)
+			},
+		},
+	}
+}
+
+// ideSet are the IDEs a handler renders, with their assets
+type ideSet struct {
+	defaultIDE  string
+	selectable  bool
+	prefillOnly bool
+	renderers   map[string]IDERenderer
+	assets      *assetsHandler
+	csp         *contentSecurityPolicy
+}
+
+// newIDESet returns the IDEs of the handler config. Only the default IDE can
+// be rendered unless the IDEs are selectable, and only the assets of the IDEs
+// which can be rendered, and are not CDN only, are self-hosted. The URLs of
+// the self-hosted assets are resolved with the endpoints.
+func newIDESet(p *Config, endpoints *endpointResolver) *ideSet {
+	var config IDEConfig
+	if p.IDE != nil {
+		config = *p.IDE
+	}
+	s := &ideSet{
+		defaultIDE:  config.Default,
+		selectable:  config.Selectable,
+		prefillOnly: config.PrefillOnly,
+		renderers:   map[string]IDERenderer{},
+	}
+	if s.defaultIDE == "" && p.GraphiQL {
+		s.defaultIDE = IDEGraphiQL
+	} else if s.defaultIDE == "" && p.Playground {
+		s.defaultIDE = IDEPlayground
+	}
+
+	builtins := builtinIDEs(p)
+	for name := range builtins {
+		if _, ok := config.Renderers[name]; ok || (!config.Selectable && name != s.defaultIDE) {
+			delete(builtins, name)
+		}
+	}
+	if p.AssetsPath != "" {
+		var manifests []map[string]asset
+		for _, ide := range builtins {
+			if !ide.cdnOnly {
+				manifests = append(manifests, ide.manifest)
+			}
+		}
+		s.assets = newAssetsHandler(p.AssetsFS, p.AssetsPath, manifests...)
+	}
+	for name, ide := range builtins {
+		assets := s.assets
+		if ide.cdnOnly {
+			assets = nil
+		}
+		s.renderers[name] = assetsRenderer{ide: ide, assets: assets, endpoints: endpoints}
+	}
+	for name, renderer := range config.Renderers {
+		s.renderers[name] = renderer
+	}
+	s.csp = newContentSecurityPolicy(p.ContentSecurityPolicy, builtins, s.assets != nil)
+	return s
+}
+
+// renderer returns the IDE to render the request with, if any. The IDE
+// chosen with the ide query parameter falls back to the default one when it
+// is unknown.
+func (s *ideSet) renderer(r *http.Request) IDERenderer {
+	if s == nil {
+		return nil
+	}
+	if s.selectable {
+		if renderer, ok := s.renderers[r.URL.Query().Get(IDEQueryParam)]; ok {
+			return renderer
+		}
+	}
+	return s.renderers[s.defaultIDE]
+}
+
+// servesAssets reports whether the request is for a self-hosted asset
+func (s *ideSet) servesAssets(r *http.Request) bool {
+	return s != nil && s.assets.serves(r)
+}
+
+// parseIDETemplate parses the page template of an IDE, with the assets
+// templates and the blocks overriding its named blocks. It returns the "index"
+// template the page is rendered with.
+func parseIDETemplate(name string, text string, blocks map[string]string) *template.Template {
+	t := template.Must(template.New(name).Parse(text + assetsTemplate))
+	for block, blockText := range blocks {
+		template.Must(t.New(block).Parse(blockText))
+	}
+	return t.Lookup("index")
+}
+
+// customIDETemplate returns a copy of a custom page template, with the assets
+// templates unless it defines its own
+func customIDETemplate(custom *template.Template) *template.Template {
+	t := template.Must(custom.Clone())
+	if t.Lookup("stylesheet") == nil && t.Lookup("script") == nil {
+		template.Must(t.Parse(assetsTemplate))
+	}
+	return t
+}
+
+// wantsIDE tells whether the request comes from a browser asking for a page
+func wantsIDE(r *http.Request) bool {
+	acceptHeader := r.Header.Get("Accept")
+	_, raw := r.URL.Query()["raw"]
+	return !raw && !strings.Contains(acceptHeader, "application/json") && strings.Contains(acceptHeader, "text/html")
+}
+
+// assetsRenderer renders a built-in IDE with its assets, self-hosted under
+// the path the client reaches the handler at, or loaded from the CDN
+type assetsRenderer struct {
+	ide       builtinIDE
+	assets    *assetsHandler
+	endpoints *endpointResolver
+}
+
+// Render renders the IDE
+func (a assetsRenderer) Render(w http.ResponseWriter, r *http.Request, ctx IDEContext) {
+	a.ide.render(w, r, ctx, a.assets.refs(a.ide.manifest, func(p string) string {
+		return a.endpoints.path(r, p)
+	}))
+}
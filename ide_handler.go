@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"io/fs"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+)
+
+// defaultBasicAuthRealm is the realm of the basic authentication of the IDE
+// handler
+const defaultBasicAuthRealm = "GraphQL IDE"
+
+// IDEHandlerConfig configures an IDE handler
+type IDEHandlerConfig struct {
+	// Endpoint is the path of the GraphQL endpoint the IDE queries, e.g.
+	// /graphql
+	Endpoint              string
+	IDE                   *IDEConfig
+	GraphiQLConfig        *GraphiQLConfig
+	PlaygroundConfig      *PlaygroundConfig
+	AssetsPath            string
+	AssetsFS              fs.FS
+	Endpoints             *EndpointConfig
+	ContentSecurityPolicy *ContentSecurityPolicyConfig
+	// Disabled responds 404 Not Found instead of rendering the IDE, e.g. in
+	// production
+	Disabled bool
+	// BasicAuth protects the IDE with HTTP basic authentication
+	BasicAuth *BasicAuth
+}
+
+// BasicAuth are the credentials of HTTP basic authentication, the username
+// and the password being required unless the IDE is disabled
+type BasicAuth struct {
+	Username string
+	Password string
+	// Realm defaults to "GraphQL IDE"
+	Realm string
+}
+
+// IDEHandler renders an IDE querying a GraphQL endpoint served by another
+// handler, so that the GraphQL endpoint never renders HTML
+type IDEHandler struct {
+	endpoint  string
+	ides      *ideSet
+	endpoints *endpointResolver
+	disabled  bool
+	basicAuth *BasicAuth
+}
+
+// NewIDE returns an IDE handler. It renders GraphiQL unless another IDE is
+// configured, and never executes the queries it is prefilled with.
+func NewIDE(p *IDEHandlerConfig) *IDEHandler {
+	if p == nil {
+		p = &IDEHandlerConfig{}
+	}
+	if p.Endpoint == "" {
+		panic("undefined GraphQL endpoint")
+	}
+
+	basicAuth := p.BasicAuth
+	if basicAuth != nil && !p.Disabled && (basicAuth.Username == "" || basicAuth.Password == "") {
+		panic("undefined basic authentication credentials")
+	}
+	if basicAuth != nil && basicAuth.Realm == "" {
+		basicAuth = &BasicAuth{
+			Username: basicAuth.Username,
+			Password: basicAuth.Password,
+			Realm:    defaultBasicAuthRealm,
+		}
+	}
+
+	endpoints := newEndpointResolver(p.Endpoints)
+	return &IDEHandler{
+		endpoint: p.Endpoint,
+		ides: newIDESet(&Config{
+			GraphiQL:              true,
+			IDE:                   p.IDE,
+			GraphiQLConfig:        p.GraphiQLConfig,
+			PlaygroundConfig:      p.PlaygroundConfig,
+			AssetsPath:            p.AssetsPath,
+			AssetsFS:              p.AssetsFS,
+			ContentSecurityPolicy: p.ContentSecurityPolicy,
+		}, endpoints),
+		endpoints: endpoints,
+		disabled:  p.Disabled,
+		basicAuth: basicAuth,
+	}
+}
+
+// ServeHTTP renders the IDE, prefilled with the query of the request
+func (h *IDEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.disabled {
+		http.NotFound(w, r)
+		return
+	}
+
+	if h.basicAuth != nil && !h.basicAuth.authorized(r) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Basic realm=%q, charset="UTF-8"`, h.basicAuth.Realm))
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if h.ides.servesAssets(r) {
+		h.ides.assets.ServeHTTP(w, r)
+		return
+	}
+
+	renderer := h.ides.renderer(r)
+	if renderer == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	opts := NewRequestOptions(r)
+	renderer.Render(w, r, IDEContext{
+		Params: graphql.Params{
+			RequestString:  opts.Query,
+			VariableValues: opts.Variables,
+			OperationName:  opts.OperationName,
+			Context:        r.Context(),
+		},
+		Endpoint:             h.endpoints.path(r, h.endpoint),
+		SubscriptionEndpoint: h.endpoints.subscriptionEndpoint(r),
+		Nonce:                h.ides.csp.apply(w),
+	})
+}
+
+// authorized tells whether the request has the basic authentication
+// credentials, compared in constant time
+func (b *BasicAuth) authorized(r *http.Request) bool {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	usernameSum, expectedUsernameSum := sha256.Sum256([]byte(username)), sha256.Sum256([]byte(b.Username))
+	passwordSum, expectedPasswordSum := sha256.Sum256([]byte(password)), sha256.Sum256([]byte(b.Password))
+	usernameMatch := subtle.ConstantTimeCompare(usernameSum[:], expectedUsernameSum[:])
+	passwordMatch := subtle.ConstantTimeCompare(passwordSum[:], expectedPasswordSum[:])
+	return usernameMatch&passwordMatch == 1
+}
@@ -0,0 +1,169 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/handler"
+)
+
+func TestIDEHandler(t *testing.T) {
+	cases := map[string]struct {
+		config               *handler.IDEHandlerConfig
+		url                  string
+		expectedStatusCode   int
+		expectedBodyContains []string
+	}{
+		"renders GraphiQL querying the endpoint": {
+			config:             &handler.IDEHandlerConfig{Endpoint: "/graphql"},
+			url:                "/graphiql?query={hero{name}}",
+			expectedStatusCode: http.StatusOK,
+			expectedBodyContains: []string{
+				`var fetchURL = "/graphql" + locationQuery(otherParams);`,
+				`var query = "{hero{name}}";`,
+				`props.response = "";`,
+			},
+		},
+		"renders the legacy GraphiQL querying the endpoint": {
+			config: &handler.IDEHandlerConfig{
+				Endpoint:       "/graphql",
+				GraphiQLConfig: &handler.GraphiQLConfig{Legacy: true},
+			},
+			url:                  "/graphiql",
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: []string{`var fetchURL = "/graphql" + locationQuery(otherParams);`},
+		},
+		"renders the configured IDE": {
+			config: &handler.IDEHandlerConfig{
+				Endpoint: "/graphql",
+				IDE:      &handler.IDEConfig{Default: handler.IDEPlayground},
+			},
+			url:                  "/playground",
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: []string{`endpoint: "/graphql",`},
+		},
+		"prepends the mount prefix to the endpoint": {
+			config: &handler.IDEHandlerConfig{
+				Endpoint:  "/graphql",
+				Endpoints: &handler.EndpointConfig{PathPrefix: "/api"},
+			},
+			url:                  "/graphiql",
+			expectedStatusCode:   http.StatusOK,
+			expectedBodyContains: []string{`var fetchURL = "/api/graphql" + locationQuery(otherParams);`},
+		},
+		"responds not found when disabled": {
+			config:             &handler.IDEHandlerConfig{Endpoint: "/graphql", Disabled: true},
+			url:                "/graphiql",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			h := handler.NewIDE(tc.config)
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com"+tc.url, nil)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Fatalf("wrong status code, expected %v, got %v", tc.expectedStatusCode, rr.Code)
+			}
+			body := rr.Body.String()
+			for _, e := range tc.expectedBodyContains {
+				if !strings.Contains(body, e) {
+					t.Fatalf("wrong body, expected %s to contain %s", body, e)
+				}
+			}
+		})
+	}
+}
+
+func TestIDEHandler_BasicAuth(t *testing.T) {
+	h := handler.NewIDE(&handler.IDEHandlerConfig{
+		Endpoint:  "/graphql",
+		BasicAuth: &handler.BasicAuth{Username: "admin", Password: "secret"},
+	})
+
+	cases := map[string]struct {
+		username           string
+		password           string
+		expectedStatusCode int
+	}{
+		"rejects missing credentials": {
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		"rejects wrong credentials": {
+			username:           "admin",
+			password:           "wrong",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		"accepts the credentials": {
+			username:           "admin",
+			password:           "secret",
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/graphiql", nil)
+			if tc.username != "" {
+				req.SetBasicAuth(tc.username, tc.password)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Fatalf("wrong status code, expected %v, got %v", tc.expectedStatusCode, rr.Code)
+			}
+			if tc.expectedStatusCode == http.StatusUnauthorized {
+				if challenge := rr.Header().Get("WWW-Authenticate"); challenge != `Basic realm="GraphQL IDE", charset="UTF-8"` {
+					t.Fatalf("wrong WWW-Authenticate header %s", challenge)
+				}
+			}
+		})
+	}
+}
+
+func TestNewIDE_UndefinedEndpoint(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected to panic, did not panic")
+		}
+	}()
+	handler.NewIDE(&handler.IDEHandlerConfig{})
+}
+
+func TestNewIDE_EmptyBasicAuthCredentials(t *testing.T) {
+	for _, basicAuth := range []*handler.BasicAuth{
+		{Username: "admin"},
+		{Password: "s3cr3t"},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("expected to panic with %+v, did not panic", basicAuth)
+				}
+			}()
+			handler.NewIDE(&handler.IDEHandlerConfig{Endpoint: "/graphql", BasicAuth: basicAuth})
+		}()
+	}
+}
+
+func TestNewIDE_DisabledWithoutBasicAuthCredentials(t *testing.T) {
+	h := handler.NewIDE(&handler.IDEHandlerConfig{
+		Endpoint:  "/graphql",
+		Disabled:  true,
+		BasicAuth: &handler.BasicAuth{Username: "admin"},
+	})
+
+	req, _ := http.NewRequest("GET", "/graphiql", nil)
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+}
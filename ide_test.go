@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_IDE(t *testing.T) {
+	custom := handler.IDERendererFn(func(w http.ResponseWriter, r *http.Request, ctx handler.IDEContext) {
+		w.Write([]byte("custom IDE for " + ctx.Endpoint + " and " + ctx.SubscriptionEndpoint + " with " + ctx.Params.RequestString))
+	})
+
+	cases := map[string]struct {
+		config               *handler.Config
+		url                  string
+		expectedContentType  string
+		expectedBodyContains string
+	}{
+		"renders GraphiQL by default": {
+			config:               &handler.Config{GraphiQL: true, Playground: true},
+			expectedBodyContains: "<title>GraphiQL</title>",
+		},
+		"renders Playground": {
+			config:               &handler.Config{Playground: true},
+			expectedBodyContains: "<title>GraphQL Playground</title>",
+		},
+		"renders Altair": {
+			config:               &handler.Config{IDE: &handler.IDEConfig{Default: handler.IDEAltair}},
+			expectedBodyContains: "AltairGraphQL.init(",
+		},
+		"renders Apollo Sandbox": {
+			config:               &handler.Config{IDE: &handler.IDEConfig{Default: handler.IDEApolloSandbox}},
+			expectedBodyContains: "new window.EmbeddedSandbox(",
+		},
+		"renders GraphQL Voyager": {
+			config:               &handler.Config{IDE: &handler.IDEConfig{Default: handler.IDEVoyager}},
+			expectedBodyContains: "GraphQLVoyager.renderVoyager(",
+		},
+		"renders the IDE chosen with the ide query parameter": {
+			config:               &handler.Config{GraphiQL: true, IDE: &handler.IDEConfig{Selectable: true}},
+			url:                  "?ide=voyager",
+			expectedBodyContains: "GraphQLVoyager.renderVoyager(",
+		},
+		"renders the default IDE when the chosen one is unknown": {
+			config:               &handler.Config{GraphiQL: true, IDE: &handler.IDEConfig{Selectable: true}},
+			url:                  "?ide=unknown",
+			expectedBodyContains: "<title>GraphiQL</title>",
+		},
+		"ignores the ide query parameter unless selectable": {
+			config:               &handler.Config{GraphiQL: true},
+			url:                  "?ide=voyager",
+			expectedBodyContains: "<title>GraphiQL</title>",
+		},
+		"renders a custom IDE": {
+			config: &handler.Config{IDE: &handler.IDEConfig{
+				Default:   "custom",
+				Renderers: map[string]handler.IDERenderer{"custom": custom},
+			}},
+			url:                  "?query={hero{name}}",
+			expectedBodyContains: "custom IDE for /graphql and ws://example.com/subscriptions with {hero{name}}",
+		},
+		"replaces a built-in IDE": {
+			config: &handler.Config{GraphiQL: true, IDE: &handler.IDEConfig{
+				Renderers: map[string]handler.IDERenderer{handler.IDEGraphiQL: custom},
+			}},
+			expectedBodyContains: "custom IDE",
+		},
+		"renders JSON without IDE": {
+			config:               &handler.Config{IDE: &handler.IDEConfig{Selectable: true}},
+			expectedContentType:  "application/json; charset=utf-8",
+			expectedBodyContains: `"data"`,
+		},
+		"renders JSON when introspection is denied": {
+			config:               &handler.Config{GraphiQL: true, Introspection: handler.DenyIntrospection},
+			expectedContentType:  "application/json; charset=utf-8",
+			expectedBodyContains: `"data"`,
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/graphql"+tc.url, nil)
+			req.Header.Set("Accept", "text/html")
+
+			tc.config.Schema = &testutil.StarWarsSchema
+			h := handler.New(tc.config)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if tc.expectedContentType != "" {
+				if contentType := rr.Header().Get("Content-Type"); contentType != tc.expectedContentType {
+					t.Fatalf("wrong content type, expected %s, got %s", tc.expectedContentType, contentType)
+				}
+			}
+			body := rr.Body.String()
+			if !strings.Contains(body, tc.expectedBodyContains) {
+				t.Fatalf("wrong body, expected %s to contain %s", body, tc.expectedBodyContains)
+			}
+		})
+	}
+}
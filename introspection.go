@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// IntrospectionFn reports whether introspection queries are allowed for the
+// request context. It may look at the principal or the role set in the
+// context by a middleware to restrict introspection to some users.
+type IntrospectionFn func(ctx context.Context) bool
+
+// AllowIntrospection allows introspection queries for every request
+func AllowIntrospection(ctx context.Context) bool {
+	return true
+}
+
+// DenyIntrospection rejects introspection queries for every request
+func DenyIntrospection(ctx context.Context) bool {
+	return false
+}
+
+// introspectionAllowed reports whether introspection is allowed for ctx,
+// introspection being allowed when no policy is configured
+func (h *Handler) introspectionAllowed(ctx context.Context) bool {
+	return h.introspection == nil || h.introspection(ctx)
+}
+
+// introspectionRule returns a validation rule rejecting the documents
+// selecting the `__schema` or `__type` fields when introspection is denied.
+// The `__typename` field is always allowed.
+func introspectionRule(introspection IntrospectionFn) validationRuleFn {
+	return func(ctx context.Context, doc *ast.Document) []gqlerrors.FormattedError {
+		if introspection(ctx) {
+			return nil
+		}
+		var errs []gqlerrors.FormattedError
+		walkFields(doc, func(field *ast.Field) {
+			if field.Name == nil {
+				return
+			}
+			if name := field.Name.Value; name == "__schema" || name == "__type" {
+				errs = append(errs, newValidationError(
+					`GraphQL introspection is not allowed, but the query contained "`+name+`".`,
+					ErrorCodeIntrospectionDisabled,
+					field,
+				))
+			}
+		})
+		return errs
+	}
+}
@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_Introspection(t *testing.T) {
+	adminOnly := func(ctx context.Context) bool {
+		return ctx.Value("role") == "admin"
+	}
+
+	cases := map[string]struct {
+		introspection handler.IntrospectionFn
+		role          string
+		query         string
+		denied        bool
+	}{
+		"allows introspection without policy": {
+			query: "{__schema{queryType{name}}}",
+		},
+		"allows introspection": {
+			introspection: handler.AllowIntrospection,
+			query:         "{__schema{queryType{name}}}",
+		},
+		"denies __schema": {
+			introspection: handler.DenyIntrospection,
+			query:         "{__schema{queryType{name}}}",
+			denied:        true,
+		},
+		"denies __type in fragments": {
+			introspection: handler.DenyIntrospection,
+			query:         `{...F} fragment F on Query {__type(name:"Droid"){name}}`,
+			denied:        true,
+		},
+		"allows __typename": {
+			introspection: handler.DenyIntrospection,
+			query:         "{hero{__typename}}",
+		},
+		"allows introspection for the predicate": {
+			introspection: adminOnly,
+			role:          "admin",
+			query:         "{__schema{queryType{name}}}",
+		},
+		"denies introspection for the predicate": {
+			introspection: adminOnly,
+			role:          "guest",
+			query:         "{__schema{queryType{name}}}",
+			denied:        true,
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			h := handler.New(&handler.Config{
+				Schema:        &testutil.StarWarsSchema,
+				Introspection: tc.introspection,
+			})
+
+			req, _ := http.NewRequest("GET", "/graphql", nil)
+			req.URL.RawQuery = "query=" + tc.query
+			req = req.WithContext(context.WithValue(req.Context(), "role", tc.role))
+			result, resp := executeTest(t, h, req)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("unexpected server response %v", resp.Code)
+			}
+			if !tc.denied {
+				if result.HasErrors() {
+					t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+				}
+				return
+			}
+			if result.Data != nil {
+				t.Fatalf("expected no data, got %v", result.Data)
+			}
+			if len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != handler.ErrorCodeIntrospectionDisabled {
+				t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+			}
+			if len(result.Errors[0].Locations) != 1 {
+				t.Fatalf("expected the error to be located, got %v", result.Errors[0].Locations)
+			}
+		})
+	}
+}
+
+func TestHandler_Introspection_DisablesIDEs(t *testing.T) {
+	h := handler.New(&handler.Config{
+		Schema:        &testutil.StarWarsSchema,
+		GraphiQL:      true,
+		Introspection: handler.DenyIntrospection,
+	})
+
+	req, _ := http.NewRequest("GET", "/graphql", nil)
+	req.Header.Set("Accept", "text/html")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+
+	if contentType := resp.Header().Get("Content-Type"); contentType != "application/json; charset=utf-8" {
+		t.Fatalf("wrong content type, expected application/json, got %s", contentType)
+	}
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/lexer"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// QueryLimits bounds the size of the query documents accepted by the handler.
+// The limits are enforced before execution, a zero value disabling a limit.
+type QueryLimits struct {
+	// MaxAliases is the maximum number of aliased fields of an operation, the
+	// fragments counting at each of their spreads
+	MaxAliases int
+	// MaxRootFields is the maximum number of root fields of an operation
+	MaxRootFields int
+	// MaxDirectivesPerField is the maximum number of directives on a field
+	MaxDirectivesPerField int
+	// MaxTokens is the maximum number of tokens in the document, it is
+	// checked before the document is parsed
+	MaxTokens int
+}
+
+// checkTokens returns an error when the request string holds more tokens than
+// allowed. Lexing errors are left to the parser.
+func checkTokens(src *source.Source, maxTokens int) []gqlerrors.FormattedError {
+	next := lexer.Lex(src)
+	for count := 0; ; count++ {
+		token, err := next(0)
+		if err != nil || token.Kind == lexer.EOF {
+			return nil
+		}
+		if count >= maxTokens {
+			return []gqlerrors.FormattedError{
+				newCodedError(fmt.Sprintf("The query exceeds the maximum of %d tokens.", maxTokens), ErrorCodeTokenLimitExceeded),
+			}
+		}
+	}
+}
+
+// queryLimitsRule returns a validation rule enforcing the alias, root field
+// and directive limits. At most one error is reported per limit.
+func queryLimitsRule(limits QueryLimits) validationRuleFn {
+	return func(ctx context.Context, doc *ast.Document) []gqlerrors.FormattedError {
+		var errs []gqlerrors.FormattedError
+
+		if limits.MaxRootFields > 0 {
+			fragments := fragmentDefinitions(doc)
+			for _, definition := range doc.Definitions {
+				operation, ok := definition.(*ast.OperationDefinition)
+				if !ok {
+					continue
+				}
+				count := countRootFields(operation.SelectionSet, fragments, map[string]bool{})
+				if count > limits.MaxRootFields {
+					errs = append(errs, newValidationError(
+						fmt.Sprintf("The operation selects %d root fields, which exceeds the maximum of %d.", count, limits.MaxRootFields),
+						ErrorCodeRootFieldLimitExceeded,
+						operation,
+					))
+					break
+				}
+			}
+		}
+
+		if limits.MaxAliases > 0 {
+			counter := &aliasCounter{
+				fragments: fragmentDefinitions(doc),
+				limit:     limits.MaxAliases,
+				counts:    map[string]int{},
+				visiting:  map[string]bool{},
+			}
+			for _, definition := range doc.Definitions {
+				operation, ok := definition.(*ast.OperationDefinition)
+				if !ok {
+					continue
+				}
+				if counter.count(operation.SelectionSet) > limits.MaxAliases {
+					errs = append(errs, newValidationError(
+						fmt.Sprintf("The query exceeds the maximum of %d aliases.", limits.MaxAliases),
+						ErrorCodeAliasLimitExceeded,
+						operation,
+					))
+					break
+				}
+			}
+		}
+
+		var directiveError *gqlerrors.FormattedError
+		walkFields(doc, func(field *ast.Field) {
+			if limits.MaxDirectivesPerField > 0 && len(field.Directives) > limits.MaxDirectivesPerField && directiveError == nil {
+				err := newValidationError(
+					fmt.Sprintf("The field has %d directives, which exceeds the maximum of %d.", len(field.Directives), limits.MaxDirectivesPerField),
+					ErrorCodeDirectiveLimitExceeded,
+					field,
+				)
+				directiveError = &err
+			}
+		})
+		if directiveError != nil {
+			errs = append(errs, *directiveError)
+		}
+
+		return errs
+	}
+}
+
+// fragmentDefinitions returns the fragment definitions of the document by name
+func fragmentDefinitions(doc *ast.Document) map[string]*ast.FragmentDefinition {
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+	return fragments
+}
+
+// countRootFields counts the fields of the selection set, following the
+// inline fragments and the fragment spreads
+func countRootFields(selectionSet *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, visited map[string]bool) int {
+	if selectionSet == nil {
+		return 0
+	}
+	count := 0
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			count++
+		case *ast.InlineFragment:
+			count += countRootFields(selection.SelectionSet, fragments, visited)
+		case *ast.FragmentSpread:
+			if selection.Name == nil || visited[selection.Name.Value] {
+				continue
+			}
+			visited[selection.Name.Value] = true
+			if fragment, ok := fragments[selection.Name.Value]; ok {
+				count += countRootFields(fragment.SelectionSet, fragments, visited)
+			}
+		}
+	}
+	return count
+}
+
+// aliasCounter counts the aliased fields of a selection set, expanding the
+// fragment spreads at each of their uses. The counts of the fragments are
+// memoized and saturate above the limit.
+type aliasCounter struct {
+	fragments map[string]*ast.FragmentDefinition
+	limit     int
+	counts    map[string]int
+	visiting  map[string]bool
+}
+
+func (c *aliasCounter) count(selectionSet *ast.SelectionSet) int {
+	if selectionSet == nil {
+		return 0
+	}
+	count := 0
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.Alias != nil {
+				count++
+			}
+			count += c.count(selection.SelectionSet)
+		case *ast.InlineFragment:
+			count += c.count(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if selection.Name != nil {
+				count += c.countFragment(selection.Name.Value)
+			}
+		}
+		if count > c.limit {
+			return c.limit + 1
+		}
+	}
+	return count
+}
+
+// countFragment returns the memoized count of a fragment. Fragment cycles are
+// left to the validation of graphql.Do.
+func (c *aliasCounter) countFragment(name string) int {
+	if count, ok := c.counts[name]; ok {
+		return count
+	}
+	fragment, ok := c.fragments[name]
+	if !ok || c.visiting[name] {
+		return 0
+	}
+	c.visiting[name] = true
+	count := c.count(fragment.SelectionSet)
+	c.visiting[name] = false
+	c.counts[name] = count
+	return count
+}
@@ -0,0 +1,76 @@
+package handler_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_QueryLimits(t *testing.T) {
+	limits := &handler.QueryLimits{
+		MaxAliases:            2,
+		MaxRootFields:         2,
+		MaxDirectivesPerField: 1,
+		MaxTokens:             30,
+	}
+
+	cases := map[string]struct {
+		query        string
+		expectedCode string
+	}{
+		"accepts queries within the limits": {
+			query: "{a: hero{name} b: hero{name}}",
+		},
+		"rejects too many aliases": {
+			query:        "{hero{a: name b: name c: name}}",
+			expectedCode: handler.ErrorCodeAliasLimitExceeded,
+		},
+		"rejects aliases multiplied by fragment spreads": {
+			query:        "{...F ...F} fragment F on Query {...G ...G} fragment G on Query {a: hero{name}}",
+			expectedCode: handler.ErrorCodeAliasLimitExceeded,
+		},
+		"rejects too many root fields": {
+			query:        "{hero{name} ...F} fragment F on Query {__typename hero{id}}",
+			expectedCode: handler.ErrorCodeRootFieldLimitExceeded,
+		},
+		"rejects too many directives per field": {
+			query:        "{hero{name @include(if:true) @skip(if:false)}}",
+			expectedCode: handler.ErrorCodeDirectiveLimitExceeded,
+		},
+		"rejects too many tokens": {
+			query:        "{hero{" + strings.Repeat("name ", 30) + "}}",
+			expectedCode: handler.ErrorCodeTokenLimitExceeded,
+		},
+	}
+
+	h := handler.New(&handler.Config{
+		Schema:      &testutil.StarWarsSchema,
+		QueryLimits: limits,
+	})
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/graphql", strings.NewReader(tc.query))
+			req.Header.Set("Content-Type", "application/graphql")
+			result, resp := executeTest(t, h, req)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("unexpected server response %v", resp.Code)
+			}
+			if tc.expectedCode == "" {
+				if result.HasErrors() {
+					t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+				}
+				return
+			}
+			if result.Data != nil {
+				t.Fatalf("expected no data, got %v", result.Data)
+			}
+			if len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != tc.expectedCode {
+				t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// SafeError is implemented by errors whose message can be exposed to clients
+// when the handler masks errors
+type SafeError interface {
+	error
+	IsSafe() bool
+}
+
+// MaskedErrorFn is called with the generated error ID and the original
+// formatted error every time the handler masks an error
+type MaskedErrorFn func(ctx context.Context, errorID string, err gqlerrors.FormattedError)
+
+// maskedErrorMessage is the message of the errors replacing the masked ones
+const maskedErrorMessage = "Internal server error"
+
+// maskErrors returns an ErrorFormatterFn replacing unexpected resolver errors
+// by a generic internal server error holding an error ID. The errors are first
+// formatted with formatter, if any.
+//
+// Only errors raised while resolving a field are masked, validation and
+// syntax errors are always returned as is. Errors carrying extensions, or
+// whose original error is a SafeError, are not masked either.
+func maskErrors(formatter ErrorFormatterFn, maskedErrorFn MaskedErrorFn) ErrorFormatterFn {
+	return func(ctx context.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError {
+		if formatter != nil {
+			err = formatter(ctx, err)
+		}
+		if len(err.Path) == 0 || len(err.Extensions) > 0 || isSafeError(err.OriginalError()) {
+			return err
+		}
+
+		errorID := newErrorID()
+		if maskedErrorFn != nil {
+			maskedErrorFn(ctx, errorID, err)
+		}
+
+		masked := gqlerrors.NewFormattedError(maskedErrorMessage)
+		masked.Locations = err.Locations
+		masked.Path = err.Path
+		masked.Extensions = map[string]interface{}{
+			"code":    ErrorCodeInternalServerError,
+			"errorId": errorID,
+		}
+		return masked
+	}
+}
+
+// isSafeError reports whether err, or the error it wraps, is a safe error
+func isSafeError(err error) bool {
+	for {
+		gqlErr, ok := err.(*gqlerrors.Error)
+		if !ok {
+			break
+		}
+		err = gqlErr.OriginalError
+	}
+	var safeErr SafeError
+	return errors.As(err, &safeErr) && safeErr.IsSafe()
+}
+
+// newErrorID returns a random identifier for a masked error
+func newErrorID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
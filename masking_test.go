@@ -0,0 +1,110 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/handler"
+)
+
+type safeError struct {
+	message string
+}
+
+func (e safeError) Error() string {
+	return e.message
+}
+
+func (e safeError) IsSafe() bool {
+	return true
+}
+
+type extendedError struct {
+	message string
+}
+
+func (e extendedError) Error() string {
+	return e.message
+}
+
+func (e extendedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": "NOT_FOUND"}
+}
+
+func TestHandler_MaskErrors(t *testing.T) {
+	schema := newTestSchema(t)
+
+	var maskedIDs []string
+	var maskedMessages []string
+	h := handler.New(&handler.Config{
+		Schema:     &schema,
+		MaskErrors: true,
+		MaskedErrorFn: func(ctx context.Context, errorID string, err gqlerrors.FormattedError) {
+			maskedIDs = append(maskedIDs, errorID)
+			maskedMessages = append(maskedMessages, err.Message)
+		},
+	})
+
+	cases := map[string]struct {
+		query           string
+		expectedMessage string
+		masked          bool
+	}{
+		"masks unexpected resolver errors": {
+			query:           "{unexpected}",
+			expectedMessage: "Internal server error",
+			masked:          true,
+		},
+		"does not mask safe errors": {
+			query:           "{safe}",
+			expectedMessage: "invalid input",
+		},
+		"does not mask errors with extensions": {
+			query:           "{extended}",
+			expectedMessage: "not found",
+		},
+		"does not mask validation errors": {
+			query:           "{unknown}",
+			expectedMessage: `Cannot query field "unknown" on type "Query".`,
+		},
+		"does not mask syntax errors": {
+			query:           "{",
+			expectedMessage: "Syntax Error GraphQL request (1:2) Expected Name, found EOF\n\n1: {\n    ^\n",
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			maskedIDs, maskedMessages = nil, nil
+
+			req, _ := http.NewRequest("POST", "/graphql", strings.NewReader(tc.query))
+			req.Header.Set("Content-Type", "application/graphql")
+			result, _ := executeTest(t, h, req)
+			if len(result.Errors) != 1 {
+				t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+			}
+			err := result.Errors[0]
+			if err.Message != tc.expectedMessage {
+				t.Fatalf("wrong error message, expected %q, got %q", tc.expectedMessage, err.Message)
+			}
+			if !tc.masked {
+				if len(maskedIDs) != 0 {
+					t.Fatalf("MaskedErrorFn was called when it should not have been")
+				}
+				return
+			}
+			if len(maskedIDs) != 1 || maskedMessages[0] != "pq: connection refused" {
+				t.Fatalf("MaskedErrorFn was not called with the original error: %v", maskedMessages)
+			}
+			if err.Extensions["code"] != handler.ErrorCodeInternalServerError || err.Extensions["errorId"] != maskedIDs[0] {
+				t.Fatalf("wrong error extensions: %v", err.Extensions)
+			}
+			if len(err.Path) != 1 || err.Path[0] != "unexpected" {
+				t.Fatalf("wrong error path: %v", err.Path)
+			}
+		})
+	}
+}
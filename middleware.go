@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ExecuteFunc executes a GraphQL request and returns its result
+type ExecuteFunc func(ctx context.Context, params *graphql.Params) *graphql.Result
+
+// Middleware wraps an ExecuteFunc, allowing a user to run code around the
+// execution of a request. A Middleware may short-circuit the chain by
+// returning a result without calling next.
+type Middleware func(next ExecuteFunc) ExecuteFunc
+
+// do is the innermost ExecuteFunc, it runs the handler validation rules and
+// then the request with graphql.Do, along with the handler field middlewares
+// and through the response cache if any
+func (h *Handler) do(ctx context.Context, params *graphql.Params) *graphql.Result {
+	if len(h.validationRules) > 0 || h.maxTokens > 0 {
+		if errs := h.validate(ctx, params.RequestString); len(errs) > 0 {
+			return &graphql.Result{Errors: errs}
+		}
+	}
+	ctx = h.fieldMiddlewares.withContext(ctx)
+	params.Context = ctx
+	if h.responseCache != nil {
+		return h.executeCached(ctx, params)
+	}
+	return graphql.Do(*params)
+}
+
+// chainMiddlewares composes the middlewares around the given ExecuteFunc. The
+// first middleware is the outermost one, so it is the first to be called.
+func chainMiddlewares(fn ExecuteFunc, middlewares []Middleware) ExecuteFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		fn = middlewares[i](fn)
+	}
+	return fn
+}
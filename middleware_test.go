@@ -0,0 +1,124 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_Middlewares_Order(t *testing.T) {
+	var calls []string
+	record := func(name string) handler.Middleware {
+		return func(next handler.ExecuteFunc) handler.ExecuteFunc {
+			return func(ctx context.Context, params *graphql.Params) *graphql.Result {
+				calls = append(calls, name+":before")
+				result := next(ctx, params)
+				calls = append(calls, name+":after")
+				return result
+			}
+		}
+	}
+
+	queryString := `query=query HeroNameQuery { hero { name } }`
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/graphql?%v", queryString), nil)
+
+	h := handler.New(&handler.Config{
+		Schema:      &testutil.StarWarsSchema,
+		Middlewares: []handler.Middleware{record("first"), record("second")},
+	})
+	result, resp := executeTest(t, h, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+	if result.HasErrors() {
+		t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+	}
+
+	expected := []string{"first:before", "second:before", "second:after", "first:after"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("wrong middleware order, expected %v, got %v", expected, calls)
+	}
+}
+
+func TestHandler_Middlewares_ShortCircuit(t *testing.T) {
+	expected := &graphql.Result{
+		Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError("unauthorized")},
+	}
+	nextCalled := false
+	h := handler.New(&handler.Config{
+		Schema: &testutil.StarWarsSchema,
+		Middlewares: []handler.Middleware{
+			func(next handler.ExecuteFunc) handler.ExecuteFunc {
+				return func(ctx context.Context, params *graphql.Params) *graphql.Result {
+					return expected
+				}
+			},
+			func(next handler.ExecuteFunc) handler.ExecuteFunc {
+				return func(ctx context.Context, params *graphql.Params) *graphql.Result {
+					nextCalled = true
+					return next(ctx, params)
+				}
+			},
+		},
+	})
+
+	queryString := `query=query HeroNameQuery { hero { name } }`
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/graphql?%v", queryString), nil)
+	result, _ := executeTest(t, h, req)
+	if nextCalled {
+		t.Fatalf("inner middleware was called after short-circuit")
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Message != "unauthorized" {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestHandler_Middlewares_Context(t *testing.T) {
+	myNameQuery := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Name: "name",
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Context.Value("name"), nil
+				},
+			},
+		},
+	})
+	myNameSchema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: myNameQuery,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := handler.New(&handler.Config{
+		Schema: &myNameSchema,
+		Middlewares: []handler.Middleware{
+			func(next handler.ExecuteFunc) handler.ExecuteFunc {
+				return func(ctx context.Context, params *graphql.Params) *graphql.Result {
+					return next(context.WithValue(ctx, "name", "middleware-data"), params)
+				}
+			},
+		},
+	})
+
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"name": "middleware-data",
+		},
+	}
+	req, _ := http.NewRequest("GET", "/graphql?query={name}", nil)
+	result, _ := executeTest(t, h, req)
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+	}
+}
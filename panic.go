@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicHandlerFn is called with the recovered value and the stack trace when
+// the handler recovers from a panic while serving the request r
+type PanicHandlerFn func(r *http.Request, recovered interface{}, stack []byte)
+
+// panicResponseWriter keeps track of whether the response has been started,
+// so that a recovered panic only writes an error response when it still can
+type panicResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *panicResponseWriter) WriteHeader(statusCode int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *panicResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// recoverPanic recovers from a panic raised while serving r, reports it to the
+// panic handler and responds with a generic internal server error.
+// It must be deferred.
+func (h *Handler) recoverPanic(w *panicResponseWriter, r *http.Request) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if recovered == http.ErrAbortHandler {
+		panic(recovered)
+	}
+
+	if h.panicHandler != nil {
+		h.panicHandler(r, recovered, debug.Stack())
+	}
+
+	if !w.wroteHeader {
+		writeErrors(w, http.StatusInternalServerError, newCodedError("Internal server error", ErrorCodeInternalServerError))
+	}
+}
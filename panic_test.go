@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_RecoversPanics(t *testing.T) {
+	cases := map[string]*handler.Config{
+		"panic in RootObjectFn": {
+			Schema: &testutil.StarWarsSchema,
+			RootObjectFn: func(ctx context.Context, r *http.Request) map[string]interface{} {
+				panic("root object panic")
+			},
+		},
+		"panic in middleware": {
+			Schema: &testutil.StarWarsSchema,
+			Middlewares: []handler.Middleware{
+				func(next handler.ExecuteFunc) handler.ExecuteFunc {
+					return func(ctx context.Context, params *graphql.Params) *graphql.Result {
+						panic("middleware panic")
+					}
+				},
+			},
+		},
+	}
+
+	for tcID, config := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			var recovered interface{}
+			var stack []byte
+			var request *http.Request
+			config.PanicHandler = func(r *http.Request, rec interface{}, s []byte) {
+				request, recovered, stack = r, rec, s
+			}
+			h := handler.New(config)
+
+			req, _ := http.NewRequest("GET", "/graphql?query={hero{name}}", nil)
+			result, resp := executeTest(t, h, req)
+			if resp.Code != http.StatusInternalServerError {
+				t.Fatalf("unexpected server response %v", resp.Code)
+			}
+			if len(result.Errors) != 1 {
+				t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+			}
+			if code := result.Errors[0].Extensions["code"]; code != handler.ErrorCodeInternalServerError {
+				t.Fatalf("wrong error code, expected %v, got %v", handler.ErrorCodeInternalServerError, code)
+			}
+			if request != req {
+				t.Fatalf("PanicHandler did not receive the request")
+			}
+			if !strings.Contains(recovered.(string), "panic") {
+				t.Fatalf("wrong recovered value %v", recovered)
+			}
+			if len(stack) == 0 {
+				t.Fatalf("PanicHandler did not receive the stack")
+			}
+		})
+	}
+}
+
+func TestHandler_RecoversPanics_AfterWrite(t *testing.T) {
+	h := handler.New(&handler.Config{
+		Schema: &testutil.StarWarsSchema,
+		ResultCallbackFn: func(ctx context.Context, params *graphql.Params, result *graphql.Result, responseBody []byte) {
+			panic("callback panic")
+		},
+	})
+
+	req, _ := http.NewRequest("GET", "/graphql?query={hero{name}}", nil)
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+	result := decodeResponse(t, resp)
+	if result.HasErrors() {
+		t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+	}
+}
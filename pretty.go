@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// PrettyHeader is the request header asking for a pretty printed response
+const PrettyHeader = "X-GraphQL-Pretty"
+
+// defaultPrettyIndent is the indentation of pretty printed responses used
+// unless configured otherwise
+const defaultPrettyIndent = "\t"
+
+// isPretty reports whether the response to r is pretty printed. The `pretty`
+// query parameter or the X-GraphQL-Pretty header of the request take
+// precedence over Config.Pretty: they opt in when present without value or
+// set to a true value, and opt out when set to a false value.
+func (h *Handler) isPretty(r *http.Request) bool {
+	if values, ok := r.URL.Query()["pretty"]; ok {
+		return parsePretty(values[0])
+	}
+	if values, ok := r.Header[http.CanonicalHeaderKey(PrettyHeader)]; ok {
+		return parsePretty(values[0])
+	}
+	return h.pretty
+}
+
+// parsePretty parses the value of a pretty option, an empty value meaning true
+func parsePretty(value string) bool {
+	if value == "" {
+		return true
+	}
+	pretty, err := strconv.ParseBool(value)
+	return err != nil || pretty
+}
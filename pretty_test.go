@@ -0,0 +1,80 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_Pretty(t *testing.T) {
+	cases := map[string]struct {
+		pretty         bool
+		indent         string
+		url            string
+		header         string
+		expectedIndent string
+	}{
+		"compact by default": {
+			url: "/graphql?query={hero{name}}",
+		},
+		"pretty with query parameter": {
+			url:            "/graphql?query={hero{name}}&pretty",
+			expectedIndent: "\t",
+		},
+		"pretty with header": {
+			url:            "/graphql?query={hero{name}}",
+			header:         "true",
+			expectedIndent: "\t",
+		},
+		"pretty with custom indent": {
+			indent:         "  ",
+			url:            "/graphql?query={hero{name}}&pretty=1",
+			expectedIndent: "  ",
+		},
+		"pretty with config": {
+			pretty:         true,
+			url:            "/graphql?query={hero{name}}",
+			expectedIndent: "\t",
+		},
+		"compact with query parameter opt-out": {
+			pretty: true,
+			url:    "/graphql?query={hero{name}}&pretty=false",
+		},
+		"compact with header opt-out": {
+			pretty: true,
+			url:    "/graphql?query={hero{name}}",
+			header: "0",
+		},
+	}
+
+	for tcID, tc := range cases {
+		t.Run(tcID, func(t *testing.T) {
+			h := handler.New(&handler.Config{
+				Schema:       &testutil.StarWarsSchema,
+				Pretty:       tc.pretty,
+				PrettyIndent: tc.indent,
+			})
+			req, _ := http.NewRequest("GET", tc.url, nil)
+			if tc.header != "" {
+				req.Header.Set(handler.PrettyHeader, tc.header)
+			}
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, req)
+
+			body := resp.Body.String()
+			if tc.expectedIndent == "" {
+				if body != `{"data":{"hero":{"name":"R2-D2"}}}` {
+					t.Fatalf("expected compact body, got %q", body)
+				}
+				return
+			}
+			if !strings.Contains(body, "\n"+tc.expectedIndent+`"data"`) {
+				t.Fatalf("expected body indented with %q, got %q", tc.expectedIndent, body)
+			}
+		})
+	}
+}
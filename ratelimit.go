@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate of the requests sharing the same key. Limiters
+// with a MaxCost() int method report the cost above which a request is never
+// allowed, such requests being rejected as too expensive instead of rate
+// limited.
+type RateLimiter interface {
+	// Allow consumes n tokens for key. It reports whether the request is
+	// allowed and, when it is not, how long to wait before retrying.
+	Allow(key string, n int) (allowed bool, retryAfter time.Duration)
+}
+
+// RateLimitKeyFn returns the key identifying the client of a request, e.g. an
+// API key, a user ID or an IP address
+type RateLimitKeyFn func(r *http.Request) string
+
+// RateLimitCostFn returns the number of tokens consumed by a request, costs
+// below 1 counting as 1
+type RateLimitCostFn func(r *http.Request, opts *RequestOptions) int
+
+type RateLimitConfig struct {
+	Limiter RateLimiter
+	// KeyFn defaults to RemoteAddrKey
+	KeyFn RateLimitKeyFn
+	// CostFn defaults to a cost of 1 per request
+	CostFn RateLimitCostFn
+}
+
+// RemoteAddrKey keys the requests by the IP address of the client
+func RemoteAddrKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimit consumes the tokens of the request and reports whether it is
+// allowed. Over-limit requests are answered with a 429 status, and requests
+// costing more than the limiter ever allows with a 400 status.
+func (h *Handler) rateLimit(w http.ResponseWriter, r *http.Request, opts *RequestOptions) bool {
+	keyFn := h.rateLimitConfig.KeyFn
+	if keyFn == nil {
+		keyFn = RemoteAddrKey
+	}
+	cost := 1
+	if h.rateLimitConfig.CostFn != nil {
+		cost = h.rateLimitConfig.CostFn(r, opts)
+	}
+	if cost < 1 {
+		cost = 1
+	}
+	if limiter, ok := h.rateLimitConfig.Limiter.(interface{ MaxCost() int }); ok && cost > limiter.MaxCost() {
+		writeErrors(w, http.StatusBadRequest, newCodedError(
+			fmt.Sprintf("The query costs %d tokens, which exceeds the maximum of %d.", cost, limiter.MaxCost()),
+			ErrorCodeQueryTooExpensive,
+		))
+		return false
+	}
+
+	allowed, retryAfter := h.rateLimitConfig.Limiter.Allow(keyFn(r), cost)
+	if allowed {
+		return true
+	}
+
+	// a limiter which never refills cannot tell when to retry
+	if retryAfter < time.Duration(math.MaxInt64) {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	writeErrors(w, http.StatusTooManyRequests, newCodedError("Too many requests, retry later.", ErrorCodeRateLimited))
+	return false
+}
+
+// TokenBucketLimiter is an in-memory RateLimiter giving each key a bucket of
+// burst tokens, refilled at rate tokens per second
+type TokenBucketLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucketLimiter returns a TokenBucketLimiter refilling rate tokens per
+// second, up to burst tokens per key
+func NewTokenBucketLimiter(rate float64, burst int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+// MaxCost returns the burst, the cost above which a request is never allowed
+func (l *TokenBucketLimiter) MaxCost() int {
+	return int(l.burst)
+}
+
+// Allow implements RateLimiter. A request costing more than the burst is
+// never allowed, and costs below 1 count as 1.
+func (l *TokenBucketLimiter) Allow(key string, n int) (bool, time.Duration) {
+	if n < 1 {
+		n = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	cost := float64(n)
+	if cost <= b.tokens {
+		b.tokens -= cost
+		return true, 0
+	}
+	if cost > l.burst || l.rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	return false, time.Duration((cost - b.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep drops the buckets which have been refilled, so that the memory used
+// by the limiter stays proportional to the number of active keys
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	if l.rate <= 0 {
+		return
+	}
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.lastSweep) < refill {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
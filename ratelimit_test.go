@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func TestTokenBucketLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewTokenBucketLimiter(1, 3)
+	l.now = func() time.Time { return now }
+
+	if allowed, _ := l.Allow("a", 2); !allowed {
+		t.Fatalf("expected request to be allowed")
+	}
+	allowed, retryAfter := l.Allow("a", 2)
+	if allowed {
+		t.Fatalf("expected request to be rejected")
+	}
+	if retryAfter != time.Second {
+		t.Fatalf("wrong retry after, expected %v, got %v", time.Second, retryAfter)
+	}
+	if allowed, _ := l.Allow("b", 3); !allowed {
+		t.Fatalf("expected request of another key to be allowed")
+	}
+
+	now = now.Add(time.Second)
+	if allowed, _ := l.Allow("a", 2); !allowed {
+		t.Fatalf("expected request to be allowed after refill")
+	}
+	if allowed, _ := l.Allow("a", 4); allowed {
+		t.Fatalf("expected request costing more than the burst to be rejected")
+	}
+	if allowed, _ := l.Allow("d", -5); !allowed || l.buckets["d"].tokens != 2 {
+		t.Fatalf("expected negative cost to count as 1, got %v tokens left", l.buckets["d"].tokens)
+	}
+
+	now = now.Add(time.Hour)
+	l.Allow("c", 1)
+	if _, ok := l.buckets["a"]; ok {
+		t.Fatalf("expected refilled buckets to be swept")
+	}
+}
+
+func TestHandler_RateLimit(t *testing.T) {
+	h := New(&Config{
+		Schema: &testutil.StarWarsSchema,
+		RateLimit: &RateLimitConfig{
+			Limiter: NewTokenBucketLimiter(0.5, 3),
+			KeyFn: func(r *http.Request) string {
+				return r.Header.Get("X-API-Key")
+			},
+			CostFn: func(r *http.Request, opts *RequestOptions) int {
+				return len(opts.Query)
+			},
+		},
+	})
+
+	serve := func(apiKey string, query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/graphql", nil)
+		req.URL.RawQuery = "query=" + query
+		req.Header.Set("X-API-Key", apiKey)
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		return resp
+	}
+
+	if resp := serve("a", "{a}"); resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+	resp := serve("a", "{a}")
+	if resp.Code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+	if retryAfter := resp.Header().Get("Retry-After"); retryAfter != "6" {
+		t.Fatalf("wrong Retry-After header, expected 6, got %v", retryAfter)
+	}
+	var result graphql.Result
+	if err := json.Unmarshal(resp.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != ErrorCodeRateLimited {
+		t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+	}
+	if resp := serve("b", "{a}"); resp.Code != http.StatusOK {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+
+	resp = serve("c", "{hero}")
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+	if retryAfter := resp.Header().Get("Retry-After"); retryAfter != "" {
+		t.Fatalf("expected no Retry-After header, got %v", retryAfter)
+	}
+	result = graphql.Result{}
+	if err := json.Unmarshal(resp.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Extensions["code"] != ErrorCodeQueryTooExpensive {
+		t.Fatalf("unexpected graphql result errors: %v", result.Errors)
+	}
+}
+
+func TestHandler_RateLimit_UndefinedLimiter(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected to panic, did not panic")
+		}
+	}()
+	New(&Config{
+		Schema:    &testutil.StarWarsSchema,
+		RateLimit: &RateLimitConfig{},
+	})
+}
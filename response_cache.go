@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// ResponseCache stores the serialized query responses
+type ResponseCache interface {
+	// Get returns the data stored for key, if any
+	Get(key string) ([]byte, bool)
+	// Set stores the data for key during ttl, tagged with tags. A zero ttl
+	// means the data does not expire.
+	Set(key string, data []byte, tags []string, ttl time.Duration)
+	// Invalidate drops the data tagged with any of the tags
+	Invalidate(tags ...string)
+}
+
+// ResponseCacheVaryFn returns what, besides the query, the variables, the
+// operation name and the authenticated Principal, the cached response of a
+// request depends on, e.g. a locale. It must cover the identity of the client
+// whenever the results depend on it beyond the Principal, e.g. on a session
+// set in the context by a middleware.
+type ResponseCacheVaryFn func(r *http.Request) string
+
+// ResponseCacheConfig enables the caching of the query responses on the
+// server. The cache is looked up once the request is validated, right before
+// it is executed: on a cache hit, the Data of the result is the cached
+// json.RawMessage and no resolver is called.
+type ResponseCacheConfig struct {
+	Cache ResponseCache
+	// TTL is how long the responses are cached, zero meaning until they are
+	// invalidated or evicted
+	TTL    time.Duration
+	VaryFn ResponseCacheVaryFn
+}
+
+// Response cache statuses reported in the `responseCache` extension
+const (
+	ResponseCacheHit  = "HIT"
+	ResponseCacheMiss = "MISS"
+)
+
+type responseCacheTagsContextKey struct{}
+
+type responseCacheVaryContextKey struct{}
+
+// withResponseCacheVary returns a copy of ctx holding what the cached
+// response of the request varies on
+func withResponseCacheVary(ctx context.Context, r *http.Request, varyFn ResponseCacheVaryFn) context.Context {
+	if varyFn == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, responseCacheVaryContextKey{}, varyFn(r))
+}
+
+// responseCacheTags collects the tags of a response
+type responseCacheTags struct {
+	mu   sync.Mutex
+	tags []string
+}
+
+// AddResponseCacheTags tags the response of the request with tags, so that
+// it can be invalidated with ResponseCache.Invalidate. It is meant to be
+// called from the resolvers with the context of graphql.ResolveParams.
+func AddResponseCacheTags(ctx context.Context, tags ...string) {
+	if t, ok := ctx.Value(responseCacheTagsContextKey{}).(*responseCacheTags); ok {
+		t.mu.Lock()
+		t.tags = append(t.tags, tags...)
+		t.mu.Unlock()
+	}
+}
+
+// responseCacheEntry is what the response cache stores for a response
+type responseCacheEntry struct {
+	Data json.RawMessage `json:"data"`
+	// CacheHint is the cache policy collected while resolving the response,
+	// restored on the hits when Config.CacheControl is set
+	CacheHint *CacheHint `json:"cacheHint,omitempty"`
+}
+
+// executeCached executes the validated request through the response cache.
+// Mutations, subscriptions and results with errors are never cached.
+func (h *Handler) executeCached(ctx context.Context, params *graphql.Params) *graphql.Result {
+	if !isQueryOperation(params.RequestString, params.OperationName) {
+		return graphql.Do(*params)
+	}
+
+	key := responseCacheKey(ctx, params)
+	policy, hasPolicy := ctx.Value(cachePolicyContextKey{}).(*cachePolicy)
+	if data, ok := h.responseCache.Cache.Get(key); ok {
+		var entry responseCacheEntry
+		if err := json.Unmarshal(data, &entry); err == nil {
+			if hasPolicy && entry.CacheHint != nil {
+				policy.restrict(*entry.CacheHint)
+			}
+			return &graphql.Result{
+				Data:       entry.Data,
+				Extensions: map[string]interface{}{"responseCache": ResponseCacheHit},
+			}
+		}
+	}
+
+	tags := &responseCacheTags{}
+	execCtx := context.WithValue(ctx, responseCacheTagsContextKey{}, tags)
+	// the policy is collected for the later hits, even when this request
+	// cannot be cached over HTTP
+	if !hasPolicy && h.cacheControl {
+		policy = &cachePolicy{}
+		execCtx = context.WithValue(execCtx, cachePolicyContextKey{}, policy)
+	}
+	params.Context = execCtx
+	result := graphql.Do(*params)
+	params.Context = ctx
+	if result.HasErrors() {
+		return result
+	}
+	if data, err := json.Marshal(result.Data); err == nil {
+		entry := responseCacheEntry{Data: data}
+		if policy != nil {
+			if hint, ok := policy.hint(); ok {
+				entry.CacheHint = &hint
+			}
+		}
+		if b, err := json.Marshal(entry); err == nil {
+			h.responseCache.Cache.Set(key, b, tags.tags, h.responseCache.TTL)
+		}
+	}
+	if result.Extensions == nil {
+		result.Extensions = map[string]interface{}{}
+	}
+	result.Extensions["responseCache"] = ResponseCacheMiss
+	return result
+}
+
+// responseCacheKey hashes what the response of the request depends on: the
+// request, the principal and what the request varies on
+func responseCacheKey(ctx context.Context, params *graphql.Params) string {
+	vary, _ := ctx.Value(responseCacheVaryContextKey{}).(string)
+	var principal interface{}
+	if p, ok := PrincipalFromContext(ctx); ok {
+		principal = []interface{}{p.ID, p.Roles, p.Scopes}
+	}
+	b, _ := json.Marshal([]interface{}{params.RequestString, params.VariableValues, params.OperationName, principal, vary})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// isQueryOperation reports whether the operation of the request string is a
+// query. Invalid request strings are not considered as queries.
+func isQueryOperation(requestString string, operationName string) bool {
+	doc, err := parser.Parse(parser.ParseParams{Source: requestString})
+	if err != nil {
+		return false
+	}
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		op, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" || (op.Name != nil && op.Name.Value == operationName) {
+			if operation != nil {
+				// the operation is ambiguous
+				return false
+			}
+			operation = op
+		}
+	}
+	return operation != nil && operation.Operation == ast.OperationTypeQuery
+}
+
+// LRUResponseCache is an in-memory ResponseCache evicting the least recently
+// used entries once it holds its maximum number of entries
+type LRUResponseCache struct {
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	tags    map[string]map[string]struct{}
+}
+
+type lruResponseCacheEntry struct {
+	key     string
+	data    []byte
+	tags    []string
+	expires time.Time
+}
+
+// NewLRUResponseCache returns an LRUResponseCache holding up to maxEntries
+// entries
+func NewLRUResponseCache(maxEntries int) *LRUResponseCache {
+	return &LRUResponseCache{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+		tags:       map[string]map[string]struct{}{},
+	}
+}
+
+// Get implements ResponseCache
+func (c *LRUResponseCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruResponseCacheEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return entry.data, true
+}
+
+// Set implements ResponseCache
+func (c *LRUResponseCache) Set(key string, data []byte, tags []string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	entry := &lruResponseCacheEntry{
+		key:  key,
+		data: data,
+		tags: tags,
+	}
+	if ttl > 0 {
+		entry.expires = c.now().Add(ttl)
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = map[string]struct{}{}
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Invalidate implements ResponseCache
+func (c *LRUResponseCache) Invalidate(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			if element, ok := c.entries[key]; ok {
+				c.remove(element)
+			}
+		}
+	}
+}
+
+// remove drops the entry of element from the cache
+func (c *LRUResponseCache) remove(element *list.Element) {
+	entry := element.Value.(*lruResponseCacheEntry)
+	c.lru.Remove(element)
+	delete(c.entries, entry.key)
+	for _, tag := range entry.tags {
+		delete(c.tags[tag], entry.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
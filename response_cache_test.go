@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func TestLRUResponseCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := NewLRUResponseCache(2)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", []byte("a"), []string{"tag"}, time.Minute)
+	c.Set("b", []byte("b"), nil, time.Minute)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatalf("expected a to be cached")
+	}
+	c.Set("c", []byte("c"), []string{"tag"}, time.Minute)
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("expected least recently used b to be evicted")
+	}
+
+	c.Invalidate("tag")
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("expected a to be invalidated")
+	}
+	if _, ok := c.Get("c"); ok {
+		t.Fatalf("expected c to be invalidated")
+	}
+
+	c.Set("d", []byte("d"), nil, time.Minute)
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("d"); ok {
+		t.Fatalf("expected d to be expired")
+	}
+
+	c.Set("e", []byte("e"), nil, 0)
+	now = now.Add(24 * time.Hour)
+	if _, ok := c.Get("e"); !ok {
+		t.Fatalf("expected e without ttl not to expire")
+	}
+}
+
+func TestHandler_ResponseCache(t *testing.T) {
+	resolved := 0
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"greeting": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					resolved++
+					AddResponseCacheTags(p.Context, "greeting")
+					return "hello", nil
+				},
+			},
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"greet": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					resolved++
+					return "hello", nil
+				},
+			},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := NewLRUResponseCache(10)
+	h := New(&Config{
+		Schema: &schema,
+		ResponseCache: &ResponseCacheConfig{
+			Cache: cache,
+			TTL:   time.Minute,
+			VaryFn: func(r *http.Request) string {
+				return r.Header.Get("Accept-Language")
+			},
+		},
+	})
+
+	serve := func(query string, language string) string {
+		req, _ := http.NewRequest("GET", "/graphql", nil)
+		req.URL.RawQuery = "query=" + query
+		req.Header.Set("Accept-Language", language)
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		return resp.Body.String()
+	}
+
+	cases := []struct {
+		query            string
+		language         string
+		expectedBody     string
+		expectedResolved int
+	}{
+		{"{greeting}", "en", `{"data":{"greeting":"hello"},"extensions":{"responseCache":"MISS"}}`, 1},
+		{"{greeting}", "en", `{"data":{"greeting":"hello"},"extensions":{"responseCache":"HIT"}}`, 1},
+		{"{greeting}", "fr", `{"data":{"greeting":"hello"},"extensions":{"responseCache":"MISS"}}`, 2},
+		{"mutation{greet}", "en", `{"data":{"greet":"hello"}}`, 3},
+		{"mutation{greet}", "en", `{"data":{"greet":"hello"}}`, 4},
+	}
+	for i, tc := range cases {
+		if body := serve(tc.query, tc.language); body != tc.expectedBody {
+			t.Fatalf("%d: wrong body, expected %s, got %s", i, tc.expectedBody, body)
+		}
+		if resolved != tc.expectedResolved {
+			t.Fatalf("%d: wrong resolver calls, expected %d, got %d", i, tc.expectedResolved, resolved)
+		}
+	}
+
+	cache.Invalidate("greeting")
+	if body := serve("{greeting}", "en"); body != `{"data":{"greeting":"hello"},"extensions":{"responseCache":"MISS"}}` {
+		t.Fatalf("expected invalidated response to be a miss, got %s", body)
+	}
+}
+
+func TestHandler_ResponseCache_AfterValidation(t *testing.T) {
+	h := New(&Config{
+		Schema: &testutil.StarWarsSchema,
+		Authenticate: func(r *http.Request) (context.Context, error) {
+			if r.Header.Get("Authorization") == "" {
+				return r.Context(), nil
+			}
+			return WithPrincipal(r.Context(), &Principal{ID: "admin"}), nil
+		},
+		Introspection: func(ctx context.Context) bool {
+			_, ok := PrincipalFromContext(ctx)
+			return ok
+		},
+		ResponseCache: &ResponseCacheConfig{
+			Cache: NewLRUResponseCache(10),
+		},
+	})
+
+	serve := func(authorization string) string {
+		req, _ := http.NewRequest("GET", "/graphql?query={__schema{queryType{name}}}", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		return resp.Body.String()
+	}
+
+	expected := `{"data":{"__schema":{"queryType":{"name":"Query"}}},"extensions":{"responseCache":"MISS"}}`
+	if body := serve("Bearer admin"); body != expected {
+		t.Fatalf("wrong body, expected %s, got %s", expected, body)
+	}
+	expected = `{"data":{"__schema":{"queryType":{"name":"Query"}}},"extensions":{"responseCache":"HIT"}}`
+	if body := serve("Bearer admin"); body != expected {
+		t.Fatalf("wrong body, expected %s, got %s", expected, body)
+	}
+	if body := serve(""); !strings.Contains(body, "INTROSPECTION_DISABLED") {
+		t.Fatalf("expected cached introspection to be rejected for anonymous clients, got %s", body)
+	}
+}
+
+func TestHandler_ResponseCache_CacheControl(t *testing.T) {
+	h := New(&Config{
+		Schema:        &testutil.StarWarsSchema,
+		CacheControl:  &CacheControlConfig{DefaultMaxAge: 60},
+		ResponseCache: &ResponseCacheConfig{Cache: NewLRUResponseCache(10)},
+	})
+
+	serve := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/graphql?query={hero{name}}", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		return resp
+	}
+
+	miss := serve("")
+	hit := serve("")
+	if !strings.Contains(hit.Body.String(), ResponseCacheHit) {
+		t.Fatalf("expected a cache hit, got %s", hit.Body.String())
+	}
+	for _, resp := range []*httptest.ResponseRecorder{miss, hit} {
+		if cacheControl := resp.Header().Get("Cache-Control"); cacheControl != "max-age=60, public" {
+			t.Fatalf("wrong Cache-Control %q", cacheControl)
+		}
+	}
+	if miss.Header().Get("ETag") == "" || hit.Header().Get("ETag") != miss.Header().Get("ETag") {
+		t.Fatalf("expected the hit ETag %q to match the miss ETag %q", hit.Header().Get("ETag"), miss.Header().Get("ETag"))
+	}
+	if resp := serve(miss.Header().Get("ETag")); resp.Code != http.StatusNotModified {
+		t.Fatalf("unexpected server response %v", resp.Code)
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+)
+
+// streamBufferSize is the size of the buffers the results are encoded through
+const streamBufferSize = 32 << 10
+
+// streamEncoder encodes the results through a buffered writer. It is pooled
+// along with its encoder, so that their buffers are reused.
+type streamEncoder struct {
+	out     io.Writer
+	bw      *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (e *streamEncoder) Write(p []byte) (int, error) {
+	return e.out.Write(p)
+}
+
+var streamEncoderPool = sync.Pool{
+	New: func() interface{} {
+		e := &streamEncoder{bw: bufio.NewWriterSize(nil, streamBufferSize)}
+		e.encoder = json.NewEncoder(e)
+		return e
+	},
+}
+
+// streamResult encodes the result directly to the response writer, instead
+// of marshaling it into a []byte first. The streamed responses have no ETag,
+// since their body is not known before it is written, and they are
+// compressed whatever their size.
+//
+// The body is also buffered and returned when the result callback needs it.
+func (h *Handler) streamResult(w http.ResponseWriter, r *http.Request, cachePolicy *cachePolicy, result *graphql.Result, pretty bool) []byte {
+	if cachePolicy != nil {
+		writeCacheControl(w, cachePolicy, result)
+	}
+
+	var dst io.Writer = w
+	var cw io.WriteCloser
+	if h.compression != nil {
+		if cw = h.compression.compressWriter(w, r); cw != nil {
+			dst = cw
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+
+	e := streamEncoderPool.Get().(*streamEncoder)
+	e.bw.Reset(dst)
+	defer func() {
+		e.out = nil
+		e.bw.Reset(nil)
+		streamEncoderPool.Put(e)
+	}()
+
+	e.out = e.bw
+	var body *bytes.Buffer
+	if h.resultCallbackFn != nil && h.resultCallbackBody {
+		body = &bytes.Buffer{}
+		e.out = io.MultiWriter(e.bw, body)
+	}
+
+	if pretty {
+		e.encoder.SetIndent("", h.prettyIndent)
+	} else {
+		e.encoder.SetIndent("", "")
+	}
+	e.encoder.Encode(result)
+	e.bw.Flush()
+	if cw != nil {
+		cw.Close()
+	}
+
+	if body == nil {
+		return nil
+	}
+	return body.Bytes()
+}
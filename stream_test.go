@@ -0,0 +1,106 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/graphql-go/handler"
+)
+
+func TestHandler_StreamResponse(t *testing.T) {
+	for _, pretty := range []bool{true, false} {
+		for _, callbackBody := range []bool{true, false} {
+			var body []byte
+			h := handler.New(&handler.Config{
+				Schema:             &testutil.StarWarsSchema,
+				Pretty:             pretty,
+				StreamResponse:     true,
+				ResultCallbackBody: callbackBody,
+				ResultCallbackFn: func(ctx context.Context, params *graphql.Params, result *graphql.Result, responseBody []byte) {
+					body = responseBody
+				},
+			})
+
+			req, _ := http.NewRequest("GET", "/graphql?query={hero{name}}", nil)
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, req)
+			respBody := resp.Body.String()
+			result := decodeResponse(t, resp)
+
+			expected := &graphql.Result{
+				Data: map[string]interface{}{
+					"hero": map[string]interface{}{
+						"name": "R2-D2",
+					},
+				},
+			}
+			if !reflect.DeepEqual(result, expected) {
+				t.Fatalf("wrong result, graphql result diff: %v", testutil.Diff(expected, result))
+			}
+			if callbackBody && string(body) != respBody {
+				t.Fatalf("wrong callback body, expected %s, got %s", respBody, body)
+			}
+			if !callbackBody && body != nil {
+				t.Fatalf("expected no callback body, got %s", body)
+			}
+		}
+	}
+}
+
+// newLargeResult returns the result of an export of many items
+func newLargeResult() *graphql.Result {
+	items := make([]interface{}, 5000)
+	for i := range items {
+		items[i] = map[string]interface{}{
+			"id":          i,
+			"description": "a rather long description of the exported item",
+		}
+	}
+	return &graphql.Result{
+		Data: map[string]interface{}{"items": items},
+	}
+}
+
+// discardResponseWriter is a ResponseWriter discarding the response, so that
+// the benchmarks only measure the allocations of the handler
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(statusCode int)  {}
+
+func benchmarkResponse(b *testing.B, pretty bool, stream bool) {
+	result := newLargeResult()
+	h := handler.New(&handler.Config{
+		Schema:         &testutil.StarWarsSchema,
+		Pretty:         pretty,
+		StreamResponse: stream,
+		// skip the execution to only measure the encoding of the result
+		Middlewares: []handler.Middleware{
+			func(next handler.ExecuteFunc) handler.ExecuteFunc {
+				return func(ctx context.Context, params *graphql.Params) *graphql.Result {
+					return result
+				}
+			},
+		},
+	})
+	req, _ := http.NewRequest("GET", "/graphql?query={hero{name}}", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(&discardResponseWriter{header: http.Header{}}, req)
+	}
+}
+
+func BenchmarkHandler_Marshal(b *testing.B)       { benchmarkResponse(b, false, false) }
+func BenchmarkHandler_Stream(b *testing.B)        { benchmarkResponse(b, false, true) }
+func BenchmarkHandler_MarshalPretty(b *testing.B) { benchmarkResponse(b, true, false) }
+func BenchmarkHandler_StreamPretty(b *testing.B)  { benchmarkResponse(b, true, true) }
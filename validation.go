@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// validationRuleFn checks a parsed document before it is executed and returns
+// the errors preventing its execution
+type validationRuleFn func(ctx context.Context, doc *ast.Document) []gqlerrors.FormattedError
+
+// validate checks the size of the request string, parses it and runs the
+// handler validation rules on it. Syntax errors are left to graphql.Do, so
+// that they are reported the same way whether the handler has validation
+// rules or not.
+func (h *Handler) validate(ctx context.Context, requestString string) []gqlerrors.FormattedError {
+	src := source.NewSource(&source.Source{
+		Body: []byte(requestString),
+		Name: "GraphQL request",
+	})
+	if h.maxTokens > 0 {
+		if errs := checkTokens(src, h.maxTokens); len(errs) > 0 {
+			return errs
+		}
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		return nil
+	}
+
+	var errs []gqlerrors.FormattedError
+	for _, rule := range h.validationRules {
+		errs = append(errs, rule(ctx, doc)...)
+	}
+	return errs
+}
+
+// newValidationError returns a formatted error located at the given nodes
+// with the given `extensions.code`
+func newValidationError(message string, code string, nodes ...ast.Node) gqlerrors.FormattedError {
+	err := gqlerrors.FormatError(gqlerrors.NewError(message, nodes, "", nil, nil, nil))
+	err.Extensions = map[string]interface{}{"code": code}
+	return err
+}
+
+// walkFields calls fn for every field selected in the document, including the
+// fields selected by the fragment definitions
+func walkFields(doc *ast.Document, fn func(field *ast.Field)) {
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			walkSelectionSet(definition.SelectionSet, fn)
+		case *ast.FragmentDefinition:
+			walkSelectionSet(definition.SelectionSet, fn)
+		}
+	}
+}
+
+func walkSelectionSet(selectionSet *ast.SelectionSet, fn func(field *ast.Field)) {
+	if selectionSet == nil {
+		return
+	}
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			fn(selection)
+			walkSelectionSet(selection.SelectionSet, fn)
+		case *ast.InlineFragment:
+			walkSelectionSet(selection.SelectionSet, fn)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// voyagerData is the page data structure of the rendered GraphQL Voyager page
+type voyagerData struct {
+	VoyagerVersion string
+	Assets         map[string]AssetRef
+	Endpoint       string
+	Nonce          string
+}
+
+// renderVoyager renders GraphQL Voyager
+func renderVoyager(w http.ResponseWriter, t *template.Template, ctx IDEContext, assets map[string]AssetRef) {
+	d := voyagerData{
+		VoyagerVersion: voyagerVersion,
+		Assets:         assets,
+		Endpoint:       ctx.Endpoint,
+		Nonce:          ctx.Nonce,
+	}
+	err := t.Execute(w, d)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// voyagerVersion is the current version of GraphQL Voyager
+const voyagerVersion = "2.0.0"
+
+// voyagerTemplate is the page template to render GraphQL Voyager
+const voyagerTemplate = `
+{{ define "index" }}
+<!--
+The request to this GraphQL server provided the header "Accept: text/html"
+and as a result has been presented GraphQL Voyager - an interactive graph of
+the GraphQL schema.
+
+If you wish to receive JSON, provide the header "Accept: application/json" or
+add "&raw" to the end of the URL within a browser.
+-->
+<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>GraphQL Voyager</title>
+  <meta name="robots" content="noindex" />
+  <meta name="referrer" content="origin">
+  <style{{ with .Nonce }} nonce="{{ . }}"{{ end }}>
+    body {
+      height: 100%;
+      margin: 0;
+      overflow: hidden;
+      width: 100%;
+    }
+    #voyager {
+      height: 100vh;
+    }
+  </style>
+  {{ template "stylesheet" index .Assets "voyager.css" }}
+  {{ template "script" index .Assets "voyager.standalone.js" }}
+</head>
+<body>
+  <div id="voyager">Loading...</div>
+  <script{{ with .Nonce }} nonce="{{ . }}"{{ end }}>
+    // Fetch the schema of the GraphQL endpoint with an introspection query.
+    var introspection = fetch({{ .Endpoint }}, {
+      method: 'post',
+      headers: {
+        'Accept': 'application/json',
+        'Content-Type': 'application/json'
+      },
+      body: JSON.stringify({ query: GraphQLVoyager.voyagerIntrospectionQuery }),
+      credentials: 'include',
+    }).then(function (response) {
+      return response.json();
+    });
+
+    GraphQLVoyager.renderVoyager(document.getElementById('voyager'), {
+      introspection: introspection,
+    });
+  </script>
+</body>
+</html>
+{{ end }}
+`